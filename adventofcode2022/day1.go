@@ -2,7 +2,6 @@ package adventofcode2022
 
 import (
 	"fmt"
-	"math"
 	"strconv"
 )
 
@@ -11,100 +10,65 @@ type IntOrSpace struct {
 	space bool
 }
 
-func ToIntOrSpaceArr(ir InputReader) ([]IntOrSpace, error) {
-	lines, err := ir.GetInput()
-	if err != nil {
-		return nil, err
-	}
-
-	converted := []IntOrSpace{}
-	for _, l := range lines {
-		if l == "" {
-			converted = append(converted, IntOrSpace{space: true})
-		} else {
+// CalorieItems passes items of all elves to fn in the input order, elves are separated by spaces
+type CalorieItems func(fn func(itm IntOrSpace)) error
+
+// ToCalorieItems parses lines only when items are iterated, so if ir is a LineScanner
+// the input is never loaded into memory
+func ToCalorieItems(ir InputReader) (CalorieItems, error) {
+	return func(fn func(itm IntOrSpace)) error {
+		line := 0
+		return ScanInput(ir, func(l string) error {
+			line++
+			if l == "" {
+				fn(IntOrSpace{space: true})
+				return nil
+			}
 			p, err := strconv.Atoi(l)
 			if err != nil {
-				return nil, err
+				return fmt.Errorf("line %v: %w", line, err)
 			}
-			converted = append(converted, IntOrSpace{v: p})
-		}
-	}
-	return converted, nil
+			fn(IntOrSpace{v: p})
+			return nil
+		})
+	}, nil
 }
 
-func Task1_1(ir InputReader, convertInput func(ir InputReader) ([]IntOrSpace, error)) (string, error) {
-
-	items, err := convertInput(ir)
+// Task1_1 keeps all totals only in debug mode to print percentiles
+func Task1_1(ir InputReader, cnvrtInpt func(InputReader) (CalorieItems, error), debug bool) (string, error) {
+	items, err := cnvrtInpt(ir)
 	if err != nil {
 		return "", err
 	}
-
-	max := 0
-	tmpSum := 0
-
-	for _, itm := range items {
-		if !itm.space {
-			tmpSum += itm.v
-		} else {
-			if tmpSum > max {
-				max = tmpSum
-			}
-			tmpSum = 0
-		}
+	stats, err := CollectCalories(items, 1, debug)
+	if err != nil {
+		return "", err
 	}
-
-	// to handle the last tmpSum
-	if tmpSum > max {
-		max = tmpSum
+	if debug {
+		fmt.Print(stats.Report(10))
 	}
 
-	return fmt.Sprintf("Day 1 Part 1 result: %v", max), nil
+	return fmt.Sprintf("Day 1 Part 1 result: %v", stats.Max), nil
 }
 
-func Task1_2(ir InputReader, convertInput func(ir InputReader) ([]IntOrSpace, error)) (string, error) {
-	items, err := convertInput(ir)
+func Task1_2(ir InputReader, cnvrtInpt func(InputReader) (CalorieItems, error), debug bool) (string, error) {
+	items, err := cnvrtInpt(ir)
 	if err != nil {
 		return "", err
 	}
-
-	max := [3]int{}
-	tmpSum := 0
-
-	for _, itm := range items {
-		if !itm.space {
-			tmpSum += itm.v
-		} else {
-			minIdx, min := getMin(max[:])
-			if tmpSum > min {
-				max[minIdx] = tmpSum
-			}
-			tmpSum = 0
-		}
+	stats, err := CollectCalories(items, 3, debug)
+	if err != nil {
+		return "", err
 	}
-
-	// to handle the last tmpSum
-	minIdx, min := getMin(max[:])
-	if tmpSum > min {
-		max[minIdx] = tmpSum
+	if debug {
+		fmt.Print(stats.Report(10))
 	}
-	tmpSum = 0
 
+	top := stats.Top()
 	total := 0
-	for _, i := range max {
+	for _, i := range top {
 		total += i
 	}
 
-	return fmt.Sprintf("Day 1 Part 2 items: %v, result: %v", max, total), nil
-}
-
-func getMin(arr []int) (int, int) {
-	min := math.MaxInt
-	i := -1
-	for idx, itm := range arr {
-		if itm < min {
-			min = itm
-			i = idx
-		}
-	}
-	return i, min
+	return fmt.Sprintf("Day 1 Part 2 items: %v, result: %v", top, total), nil
 }
@@ -2,153 +2,84 @@ package adventofcode2022
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 )
 
-type CmdState struct {
-	Cycle int
-	Value int
-	Saved []int
-}
-
-type StatefullCmd interface {
-	Execute(s *CmdState, needSave func(cycle int, state int) bool, save func(cycle int, state int) int)
-}
-
-type Addx struct {
-	Cycle int
-	Arg   int
-}
-
-func NewAddxCmd(arg int) Addx {
-	return Addx{Cycle: 2, Arg: arg}
-}
-
-func (c Addx) Execute(s *CmdState, needSave func(cycle int, state int) bool, save func(cycle int, state int) int) {
-	for i := 0; i < 2; i++ {
-		s.Cycle += 1
-		if needSave(s.Cycle, s.Value) {
-			s.Saved = append(s.Saved, save(s.Cycle, s.Value))
-		}
+func ToProgram(ir InputReader) (Program, error) {
+	lines, err := ir.GetInput()
+	if err != nil {
+		return Program{}, err
 	}
-	s.Value += c.Arg
+	return Assemble(lines, DefaultInstructionSet())
 }
 
-type Noop struct {
-	Cycle int
-}
+// day10MaxCycles limits Run of day 10 tasks, jumps make infinite loops possible
+const day10MaxCycles = 1000000
 
-func NewNoopCmd() Noop {
-	return Noop{Cycle: 1}
+// day 10 machine has a single register X
+func newDay10CPU(program Program) *CPU {
+	cpu := NewCPU(program, map[string]int{"X": 1})
+	cpu.MaxCycles = day10MaxCycles
+	return cpu
 }
 
-func (c Noop) Execute(s *CmdState, needSave func(cycle int, state int) bool, save func(cycle int, state int) int) {
-	s.Cycle += 1
-	if needSave(s.Cycle, s.Value) {
-		s.Saved = append(s.Saved, save(s.Cycle, s.Value))
-	}
-}
-
-func ToStatefulCmds(ir InputReader) ([]StatefullCmd, error) {
-	lines, err := ir.GetInput()
-	if err != nil {
-		return nil, err
-	}
-
-	cmds := []StatefullCmd{}
-	for _, line := range lines {
-		splitted := strings.Split(line, " ")
-		if len(splitted) == 0 || len(splitted) > 2 {
-			return nil, fmt.Errorf("expected format: <cmd> <optional: arg>, got: %v", line)
-		}
-		cmd := splitted[0]
-		switch cmd {
-		case "noop":
-			cmds = append(cmds, NewNoopCmd())
-		case "addx":
-			if len(splitted) != 2 {
-				return nil, fmt.Errorf("for [addx] cmd, expected: addx <arg>, got: %v", line)
-			}
-			arg, err := strconv.Atoi(splitted[1])
-			if err != nil {
-				return nil, err
-			}
-			cmds = append(cmds, NewAddxCmd(arg))
-		default:
-			return nil, fmt.Errorf("unexpected line: %v", line)
+// crtHook draws pixel on every cycle if sprite, which is 3 pixels wide and centered on X, covers it
+func crtHook(fb Framebuffer) TickHook {
+	return func(cycle int, cpu *CPU) {
+		pixel := cycle - 1
+		row := (pixel / fb.Width) % fb.Height
+		col := pixel % fb.Width
+		if Abs(col-cpu.Registers["X"]) <= 1 {
+			fb.Pixels[row][col] = true
 		}
 	}
-	return cmds, nil
 }
 
-func Task10_1(ir InputReader, cnvrtInpt func(InputReader) ([]StatefullCmd, error), debug bool) (string, error) {
-	cmnds, err := cnvrtInpt(ir)
+func Task10_1(ir InputReader, cnvrtInpt func(InputReader) (Program, error), debug bool) (string, error) {
+	program, err := cnvrtInpt(ir)
 	if err != nil {
 		return "", err
 	}
 
-	state := CmdState{Value: 1}
-
-	needSave := func(cycle int, state int) bool {
-		return cycle == 20 || (cycle-20)%40 == 0
-	}
-	save := func(cycle int, state int) int {
-		return cycle * state
-	}
-
-	for _, cmd := range cmnds {
-		cmd.Execute(&state, needSave, save)
-	}
+	cpu := newDay10CPU(program)
 
 	strength := 0
-	for _, s := range state.Saved {
-		strength += s
+	cpu.OnTick(func(cycle int, cpu *CPU) {
+		if cycle == 20 || (cycle-20)%40 == 0 {
+			strength += cycle * cpu.Registers["X"]
+		}
+	})
+
+	if err := cpu.Run(); err != nil {
+		return "", err
 	}
 
 	return fmt.Sprintf("Result: %v", strength), nil
 }
 
-func Task10_2(ir InputReader, cnvrtInpt func(InputReader) ([]StatefullCmd, error), debug bool) (string, error) {
-	cmnds, err := cnvrtInpt(ir)
+func Task10_2(ir InputReader, cnvrtInpt func(InputReader) (Program, error), debug bool) (string, error) {
+	program, err := cnvrtInpt(ir)
 	if err != nil {
 		return "", err
 	}
 
-	state := CmdState{Value: 1}
+	cpu := newDay10CPU(program)
 
-	needSave := func(cycle int, state int) bool {
-		currPixel := cycle - 1
-		if currPixel >= 40 {
-			currPixel = currPixel % 40
-		}
-		return currPixel == state-1 || currPixel == state || currPixel == state+1
-	}
-	save := func(cycle int, state int) int {
-		return cycle - 1
-	}
-	for _, cmd := range cmnds {
-		cmd.Execute(&state, needSave, save)
-	}
+	fb := NewFramebuffer(40, 6)
+	cpu.OnTick(crtHook(fb))
 
-	pic := [6][40]string{}
+	if err := cpu.Run(); err != nil {
+		return "", err
+	}
 
-	for _, i := range state.Saved {
-		row := i / 40
-		col := i % 40
-		pic[row][col] = "#"
+	if debug {
+		fmt.Printf("CRT:\n%v\n", fb)
 	}
 
-	res := strings.Builder{}
-	for i := 0; i < len(pic); i++ {
-		for j := 0; j < len(pic[i]); j++ {
-			if pic[i][j] != "#" {
-				pic[i][j] = " "
-			}
-			res.WriteString(pic[i][j])
-		}
-		res.WriteString("\n")
+	letters, err := OCR(fb)
+	if err != nil {
+		// the screen is the only way to read the answer if letters aren't recognized
+		return "", fmt.Errorf("%w, CRT:\n%v", err, fb)
 	}
 
-	return fmt.Sprintf("Result:\n%v\n", res.String()), nil
+	return fmt.Sprintf("Result: %v", letters), nil
 }
@@ -0,0 +1,121 @@
+package adventofcode2022
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Assemble parses program source, one instruction per line in format: <name> <arg1> <arg2>...
+// Arguments may be separated by spaces or commas, lines like "loop:" define labels,
+// everything after ';' or '#' is a comment
+func Assemble(lines []string, is InstructionSet) (Program, error) {
+	type pending struct {
+		def  InstructionDef
+		args []string
+		line int
+	}
+
+	labels := map[string]int{}
+	instrs := []pending{}
+	for i, line := range lines {
+		if c := strings.IndexAny(line, ";#"); c >= 0 {
+			line = line[:c]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasSuffix(line, ":") {
+			label := strings.TrimSuffix(line, ":")
+			if !isIdentifier(label) {
+				return Program{}, fmt.Errorf("line %v: invalid label: [%v]", i+1, label)
+			}
+			if _, ok := labels[label]; ok {
+				return Program{}, fmt.Errorf("line %v: duplicate label: [%v]", i+1, label)
+			}
+			labels[label] = len(instrs)
+			continue
+		}
+
+		fields := strings.Fields(strings.ReplaceAll(line, ",", " "))
+		def, ok := is[fields[0]]
+		if !ok {
+			return Program{}, fmt.Errorf("line %v: unknown instruction: [%v]", i+1, fields[0])
+		}
+		if len(fields)-1 != len(def.Args) {
+			return Program{}, fmt.Errorf("line %v: instruction [%v] expects %v args, got: %v", i+1, def.Name, len(def.Args), len(fields)-1)
+		}
+		instrs = append(instrs, pending{def, fields[1:], i + 1})
+	}
+
+	// operands are resolved after the first pass, so jumps forward to labels work
+	program := Program{
+		Instructions: make([]Instruction, len(instrs)),
+		Labels:       labels,
+	}
+	for pc, p := range instrs {
+		args := make([]Operand, len(p.args))
+		for j, a := range p.args {
+			op, err := parseOperand(a, p.def.Args[j], pc, labels)
+			if err != nil {
+				return Program{}, fmt.Errorf("line %v: %v", p.line, err)
+			}
+			args[j] = op
+		}
+		program.Instructions[pc] = Instruction{
+			Def:  p.def,
+			Args: args,
+			Line: p.line,
+		}
+	}
+	return program, nil
+}
+
+func parseOperand(s string, kind ArgKind, pc int, labels map[string]int) (Operand, error) {
+	switch kind {
+	case RegArg:
+		if !isIdentifier(s) {
+			return Operand{}, fmt.Errorf("expected register, got: [%v]", s)
+		}
+		return Operand{Kind: kind, Reg: s}, nil
+	case ValueArg:
+		if isIdentifier(s) {
+			return Operand{Kind: kind, Reg: s}, nil
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return Operand{}, fmt.Errorf("expected register or integer, got: [%v]", s)
+		}
+		return Operand{Kind: kind, Value: v}, nil
+	case TargetArg:
+		if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+			offset, err := strconv.Atoi(s)
+			if err != nil {
+				return Operand{}, fmt.Errorf("expected relative offset like +2, got: [%v]", s)
+			}
+			return Operand{Kind: kind, Value: pc + offset}, nil
+		}
+		target, ok := labels[s]
+		if !ok {
+			return Operand{}, fmt.Errorf("unknown label: [%v]", s)
+		}
+		return Operand{Kind: kind, Value: target}, nil
+	default:
+		return Operand{}, fmt.Errorf("unsupported argument kind: %v", kind)
+	}
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		letter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		digit := c >= '0' && c <= '9'
+		if !letter && !(digit && i > 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,285 @@
+package adventofcode2022
+
+import (
+	"errors"
+	"fmt"
+)
+
+type ArgKind int
+
+const (
+	// register name
+	RegArg ArgKind = iota
+	// register name or integer
+	ValueArg
+	// label or relative offset like +2, -3
+	TargetArg
+)
+
+type Operand struct {
+	Kind ArgKind
+	// register name, empty for immediate values and targets
+	Reg string
+	// immediate value or absolute instruction index for targets
+	Value int
+}
+
+func (o Operand) String() string {
+	switch {
+	case o.Reg != "":
+		return o.Reg
+	case o.Kind == TargetArg:
+		return fmt.Sprintf("@%v", o.Value)
+	default:
+		return fmt.Sprintf("%v", o.Value)
+	}
+}
+
+type InstructionDef struct {
+	Name   string
+	Cycles int
+	Args   []ArgKind
+	// Exec is called on the last cycle of instruction, by default execution continues with the next instruction,
+	// jumps call cpu.Jump
+	Exec func(cpu *CPU, args []Operand) error
+}
+
+type InstructionSet map[string]InstructionDef
+
+func (is InstructionSet) Add(def InstructionDef) {
+	is[def.Name] = def
+}
+
+func binaryOp(name string, cycles int, f func(a int, b int) (int, error)) InstructionDef {
+	return InstructionDef{
+		Name:   name,
+		Cycles: cycles,
+		Args:   []ArgKind{RegArg, ValueArg},
+		Exec: func(cpu *CPU, args []Operand) error {
+			res, err := f(cpu.Registers[args[0].Reg], cpu.Value(args[1]))
+			if err != nil {
+				return err
+			}
+			cpu.Registers[args[0].Reg] = res
+			return nil
+		},
+	}
+}
+
+func condJump(name string, cond func(v int) bool) InstructionDef {
+	return InstructionDef{
+		Name:   name,
+		Cycles: 1,
+		Args:   []ArgKind{ValueArg, TargetArg},
+		Exec: func(cpu *CPU, args []Operand) error {
+			if cond(cpu.Value(args[0])) {
+				cpu.Jump(args[1].Value)
+			}
+			return nil
+		},
+	}
+}
+
+// DefaultInstructionSet contains day 10 instructions (noop, addx) and a few general purpose ones:
+// set, add, sub, mul, div, mod, jmp, jz, jnz, jlt, jgt, halt
+func DefaultInstructionSet() InstructionSet {
+	is := InstructionSet{}
+	is.Add(InstructionDef{
+		Name:   "noop",
+		Cycles: 1,
+		Exec:   func(cpu *CPU, args []Operand) error { return nil },
+	})
+	is.Add(InstructionDef{
+		Name:   "addx",
+		Cycles: 2,
+		Args:   []ArgKind{ValueArg},
+		Exec: func(cpu *CPU, args []Operand) error {
+			cpu.Registers["X"] += cpu.Value(args[0])
+			return nil
+		},
+	})
+	is.Add(binaryOp("set", 1, func(a int, b int) (int, error) { return b, nil }))
+	is.Add(binaryOp("add", 1, func(a int, b int) (int, error) { return a + b, nil }))
+	is.Add(binaryOp("sub", 1, func(a int, b int) (int, error) { return a - b, nil }))
+	is.Add(binaryOp("mul", 1, func(a int, b int) (int, error) { return a * b, nil }))
+	is.Add(binaryOp("div", 1, func(a int, b int) (int, error) {
+		if b == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	}))
+	is.Add(binaryOp("mod", 1, func(a int, b int) (int, error) {
+		if b == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return a % b, nil
+	}))
+	is.Add(InstructionDef{
+		Name:   "jmp",
+		Cycles: 1,
+		Args:   []ArgKind{TargetArg},
+		Exec: func(cpu *CPU, args []Operand) error {
+			cpu.Jump(args[0].Value)
+			return nil
+		},
+	})
+	is.Add(condJump("jz", func(v int) bool { return v == 0 }))
+	is.Add(condJump("jnz", func(v int) bool { return v != 0 }))
+	is.Add(condJump("jlt", func(v int) bool { return v < 0 }))
+	is.Add(condJump("jgt", func(v int) bool { return v > 0 }))
+	is.Add(InstructionDef{
+		Name:   "halt",
+		Cycles: 1,
+		Exec: func(cpu *CPU, args []Operand) error {
+			cpu.Jump(len(cpu.Program.Instructions))
+			return nil
+		},
+	})
+	return is
+}
+
+type Instruction struct {
+	Def  InstructionDef
+	Args []Operand
+	// line of source code, 1-based
+	Line int
+}
+
+func (i Instruction) String() string {
+	res := i.Def.Name
+	for _, a := range i.Args {
+		res += " " + a.String()
+	}
+	return res
+}
+
+type Program struct {
+	Instructions []Instruction
+	Labels       map[string]int
+}
+
+// TickHook is called during every cycle, before the instruction which is in progress completes,
+// so registers contain values they have "during" the cycle
+type TickHook func(cycle int, cpu *CPU)
+
+var ErrHalted = errors.New("cpu halted")
+
+type CPU struct {
+	Registers map[string]int
+	// index of current instruction
+	PC int
+	// number of completed cycles
+	Cycle int
+	// number of completed cycles of current instruction
+	InstrCycle int
+	// Run stops with error after MaxCycles cycles, 0 means no limit
+	MaxCycles int
+	Program   Program
+
+	hooks []TickHook
+	next  int
+}
+
+func NewCPU(program Program, registers map[string]int) *CPU {
+	regs := map[string]int{}
+	for k, v := range registers {
+		regs[k] = v
+	}
+	return &CPU{
+		Registers: regs,
+		Program:   program,
+	}
+}
+
+func (cpu *CPU) OnTick(hook TickHook) {
+	cpu.hooks = append(cpu.hooks, hook)
+}
+
+// Halted reports whether execution ran off the end of the program, jumps outside of the program are errors
+func (cpu *CPU) Halted() bool {
+	return cpu.PC >= len(cpu.Program.Instructions)
+}
+
+// Current returns instruction in progress
+func (cpu *CPU) Current() (Instruction, bool) {
+	if cpu.Halted() || cpu.PC < 0 {
+		return Instruction{}, false
+	}
+	return cpu.Program.Instructions[cpu.PC], true
+}
+
+func (cpu *CPU) Value(o Operand) int {
+	if o.Reg != "" {
+		return cpu.Registers[o.Reg]
+	}
+	return o.Value
+}
+
+// Jump sets instruction which will be executed after the current one
+func (cpu *CPU) Jump(pc int) {
+	cpu.next = pc
+}
+
+// Tick emulates a single cycle
+func (cpu *CPU) Tick() error {
+	instr, ok := cpu.Current()
+	if !ok && cpu.PC < 0 {
+		return fmt.Errorf("instruction %v is outside of the program", cpu.PC)
+	}
+	if !ok {
+		return ErrHalted
+	}
+
+	cycle := cpu.Cycle + 1
+	for _, h := range cpu.hooks {
+		h(cycle, cpu)
+	}
+
+	cpu.Cycle = cycle
+	cpu.InstrCycle++
+	if cpu.InstrCycle < instr.Def.Cycles {
+		return nil
+	}
+
+	cpu.InstrCycle = 0
+	cpu.next = cpu.PC + 1
+	for _, a := range instr.Args {
+		if _, ok := cpu.Registers[a.Reg]; a.Reg != "" && !ok {
+			return fmt.Errorf("line %v [%v], cycle %v: unknown register: %v", instr.Line, instr, cycle, a.Reg)
+		}
+	}
+	if err := instr.Def.Exec(cpu, instr.Args); err != nil {
+		return fmt.Errorf("line %v [%v], cycle %v: %v", instr.Line, instr, cycle, err)
+	}
+	// jump right after the last instruction halts the program
+	if cpu.next < 0 || cpu.next > len(cpu.Program.Instructions) {
+		return fmt.Errorf("line %v [%v], cycle %v: jump target %v is outside of the program", instr.Line, instr, cycle, cpu.next)
+	}
+	cpu.PC = cpu.next
+	return nil
+}
+
+// StepInstruction ticks until the current instruction completes
+func (cpu *CPU) StepInstruction() error {
+	for {
+		if err := cpu.Tick(); err != nil {
+			return err
+		}
+		if cpu.InstrCycle == 0 {
+			return nil
+		}
+	}
+}
+
+// Run ticks until program ends
+func (cpu *CPU) Run() error {
+	for !cpu.Halted() {
+		if cpu.MaxCycles > 0 && cpu.Cycle >= cpu.MaxCycles {
+			return fmt.Errorf("cycle limit %v exceeded", cpu.MaxCycles)
+		}
+		if err := cpu.Tick(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
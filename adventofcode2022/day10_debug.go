@@ -0,0 +1,378 @@
+package adventofcode2022
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type BreakpointKind int
+
+const (
+	CycleBreakpoint BreakpointKind = iota
+	PCBreakpoint
+	RegBreakpoint
+)
+
+// Breakpoint is checked between cycles: cycle breakpoint stops before the cycle starts,
+// pc breakpoint stops before the instruction starts, register breakpoint stops when its condition becomes true
+type Breakpoint struct {
+	ID    int
+	Kind  BreakpointKind
+	Reg   string
+	Op    string
+	Value int
+
+	wasTrue bool
+}
+
+func (b Breakpoint) String() string {
+	switch b.Kind {
+	case CycleBreakpoint:
+		return fmt.Sprintf("#%v cycle %v", b.ID, b.Value)
+	case PCBreakpoint:
+		return fmt.Sprintf("#%v pc %v", b.ID, b.Value)
+	default:
+		return fmt.Sprintf("#%v %v %v %v", b.ID, b.Reg, b.Op, b.Value)
+	}
+}
+
+func compareOp(op string) (func(a int, b int) bool, bool) {
+	switch op {
+	case "==":
+		return func(a int, b int) bool { return a == b }, true
+	case "!=":
+		return func(a int, b int) bool { return a != b }, true
+	case "<":
+		return func(a int, b int) bool { return a < b }, true
+	case "<=":
+		return func(a int, b int) bool { return a <= b }, true
+	case ">":
+		return func(a int, b int) bool { return a > b }, true
+	case ">=":
+		return func(a int, b int) bool { return a >= b }, true
+	default:
+		return nil, false
+	}
+}
+
+func (b *Breakpoint) hit(cpu *CPU) bool {
+	switch b.Kind {
+	case CycleBreakpoint:
+		return cpu.Cycle+1 == b.Value
+	case PCBreakpoint:
+		return cpu.InstrCycle == 0 && cpu.PC == b.Value
+	default:
+		cmp, _ := compareOp(b.Op)
+		isTrue := cmp(cpu.Registers[b.Reg], b.Value)
+		hit := isTrue && !b.wasTrue
+		b.wasTrue = isTrue
+		return hit
+	}
+}
+
+// Debugger is an interactive step debugger for day 10 CPU, it reads commands line by line from in
+type Debugger struct {
+	CPU         *CPU
+	CRT         Framebuffer
+	Breakpoints []*Breakpoint
+	Watches     []string
+	// every step, next or continue stops after MaxCycles cycles, so loops can't hang the debugger, 0 means no limit
+	MaxCycles int
+
+	in     *bufio.Scanner
+	out    io.Writer
+	nextID int
+}
+
+func NewDebugger(program Program, in io.Reader, out io.Writer) *Debugger {
+	cpu := newDay10CPU(program)
+	fb := NewFramebuffer(40, 6)
+	cpu.OnTick(crtHook(fb))
+	return &Debugger{
+		CPU:       cpu,
+		CRT:       fb,
+		MaxCycles: DefaultDebuggerMaxCycles,
+		in:        bufio.NewScanner(in),
+		out:       out,
+		nextID:    1,
+	}
+}
+
+const DefaultDebuggerMaxCycles = 1000000
+
+const debuggerHelp = `commands:
+  s, step [n]                 run n cycles (default 1)
+  n, next [n]                 run n instructions (default 1)
+  c, continue                 run until breakpoint, end of program or cycle limit
+  b, break cycle <n>          stop before cycle n
+  b, break pc <n>             stop before instruction n starts
+  b, break <reg> <op> <n>     stop when condition becomes true, op: == != < <= > >=
+  d, delete <id>              delete breakpoint
+  breaks                      list breakpoints
+  w, watch <reg>              print register after every stop
+  unwatch <reg>               stop watching register
+  r, regs                     print registers
+  l, list                     print program around current instruction
+  crt                         print CRT, pixels which aren't drawn yet are shown as '.'
+  h, help                     print this help
+  q, quit                     exit
+`
+
+// Run reads and executes commands until quit or end of input
+func (d *Debugger) Run() error {
+	fmt.Fprintf(d.out, "loaded %v instructions, type 'help' for commands\n", len(d.CPU.Program.Instructions))
+	d.printState()
+	for {
+		fmt.Fprint(d.out, "(dbg) ")
+		if !d.in.Scan() {
+			fmt.Fprintln(d.out)
+			return d.in.Err()
+		}
+		fields := strings.Fields(d.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		quit, err := d.exec(fields[0], fields[1:])
+		if err != nil {
+			fmt.Fprintf(d.out, "error: %v\n", err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+func (d *Debugger) exec(cmd string, args []string) (bool, error) {
+	switch cmd {
+	case "s", "step":
+		n, err := optionalCount(args)
+		if err != nil {
+			return false, err
+		}
+		ticks := 0
+		return false, d.run(func() bool {
+			ticks++
+			return ticks >= n
+		})
+	case "n", "next":
+		n, err := optionalCount(args)
+		if err != nil {
+			return false, err
+		}
+		instrs := 0
+		return false, d.run(func() bool {
+			if d.CPU.InstrCycle == 0 {
+				instrs++
+			}
+			return instrs >= n
+		})
+	case "c", "continue":
+		return false, d.run(func() bool { return false })
+	case "b", "break":
+		return false, d.addBreakpoint(args)
+	case "d", "delete":
+		return false, d.deleteBreakpoint(args)
+	case "breaks":
+		for _, b := range d.Breakpoints {
+			fmt.Fprintln(d.out, b)
+		}
+		return false, nil
+	case "w", "watch":
+		if len(args) != 1 || !isIdentifier(args[0]) {
+			return false, fmt.Errorf("expected: watch <reg>")
+		}
+		d.Watches = append(d.Watches, args[0])
+		return false, nil
+	case "unwatch":
+		if len(args) != 1 {
+			return false, fmt.Errorf("expected: unwatch <reg>")
+		}
+		for i, w := range d.Watches {
+			if w == args[0] {
+				d.Watches = append(d.Watches[:i], d.Watches[i+1:]...)
+				return false, nil
+			}
+		}
+		return false, fmt.Errorf("register [%v] isn't watched", args[0])
+	case "r", "regs":
+		d.printRegs()
+		return false, nil
+	case "l", "list":
+		d.printListing()
+		return false, nil
+	case "crt":
+		d.printCRT()
+		return false, nil
+	case "h", "help":
+		fmt.Fprint(d.out, debuggerHelp)
+		return false, nil
+	case "q", "quit":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown command: [%v], type 'help' for commands", cmd)
+	}
+}
+
+func optionalCount(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("expected positive number, got: [%v]", args[0])
+	}
+	return n, nil
+}
+
+// run ticks until done or breakpoint, breakpoints are checked after every cycle,
+// so it's always possible to continue from a breakpoint
+func (d *Debugger) run(done func() bool) error {
+	defer d.printState()
+	for ticks := 0; ; ticks++ {
+		if d.MaxCycles > 0 && ticks >= d.MaxCycles {
+			fmt.Fprintf(d.out, "stopped, cycle limit %v reached\n", d.MaxCycles)
+			return nil
+		}
+		if err := d.CPU.Tick(); err != nil {
+			if errors.Is(err, ErrHalted) {
+				fmt.Fprintln(d.out, "program finished")
+				return nil
+			}
+			return err
+		}
+		if b := d.checkBreakpoints(); b != nil {
+			fmt.Fprintf(d.out, "breakpoint %v\n", b)
+			return nil
+		}
+		if done() {
+			return nil
+		}
+	}
+}
+
+func (d *Debugger) checkBreakpoints() *Breakpoint {
+	var res *Breakpoint
+	for _, b := range d.Breakpoints {
+		// every breakpoint has to be checked, register breakpoints track their previous state
+		if b.hit(d.CPU) && res == nil {
+			res = b
+		}
+	}
+	return res
+}
+
+func (d *Debugger) addBreakpoint(args []string) error {
+	b := &Breakpoint{ID: d.nextID}
+	switch {
+	case len(args) == 2 && (args[0] == "cycle" || args[0] == "pc"):
+		v, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("expected number, got: [%v]", args[1])
+		}
+		b.Kind = CycleBreakpoint
+		if args[0] == "pc" {
+			b.Kind = PCBreakpoint
+		}
+		b.Value = v
+	case len(args) == 3:
+		if !isIdentifier(args[0]) {
+			return fmt.Errorf("expected register, got: [%v]", args[0])
+		}
+		if _, ok := compareOp(args[1]); !ok {
+			return fmt.Errorf("unsupported comparison: [%v]", args[1])
+		}
+		v, err := strconv.Atoi(args[2])
+		if err != nil {
+			return fmt.Errorf("expected number, got: [%v]", args[2])
+		}
+		b.Kind = RegBreakpoint
+		b.Reg = args[0]
+		b.Op = args[1]
+		b.Value = v
+		cmp, _ := compareOp(b.Op)
+		b.wasTrue = cmp(d.CPU.Registers[b.Reg], b.Value)
+	default:
+		return fmt.Errorf("expected: break cycle <n> | break pc <n> | break <reg> <op> <n>")
+	}
+	d.nextID++
+	d.Breakpoints = append(d.Breakpoints, b)
+	fmt.Fprintf(d.out, "breakpoint %v\n", b)
+	return nil
+}
+
+func (d *Debugger) deleteBreakpoint(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected: delete <id>")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("expected number, got: [%v]", args[0])
+	}
+	for i, b := range d.Breakpoints {
+		if b.ID == id {
+			d.Breakpoints = append(d.Breakpoints[:i], d.Breakpoints[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("breakpoint #%v not found", id)
+}
+
+func (d *Debugger) printState() {
+	cpu := d.CPU
+	instr, ok := cpu.Current()
+	if !ok {
+		fmt.Fprintf(d.out, "cycle: %v, halted\n", cpu.Cycle)
+	} else {
+		fmt.Fprintf(d.out, "cycle: %v, pc: %v, line %v: %v (%v/%v)\n", cpu.Cycle, cpu.PC, instr.Line, instr, cpu.InstrCycle, instr.Def.Cycles)
+	}
+	for _, w := range d.Watches {
+		fmt.Fprintf(d.out, "  %v = %v\n", w, cpu.Registers[w])
+	}
+}
+
+func (d *Debugger) printRegs() {
+	regs := make([]string, 0, len(d.CPU.Registers))
+	for r := range d.CPU.Registers {
+		regs = append(regs, r)
+	}
+	sort.Strings(regs)
+	for _, r := range regs {
+		fmt.Fprintf(d.out, "%v = %v\n", r, d.CPU.Registers[r])
+	}
+}
+
+func (d *Debugger) printListing() {
+	from := Max(0, d.CPU.PC-5)
+	to := Min(len(d.CPU.Program.Instructions), d.CPU.PC+6)
+	for i := from; i < to; i++ {
+		marker := "  "
+		if i == d.CPU.PC {
+			marker = "=>"
+		}
+		fmt.Fprintf(d.out, "%v %4v: %v\n", marker, i, d.CPU.Program.Instructions[i])
+	}
+}
+
+func (d *Debugger) printCRT() {
+	drawn := d.CPU.Cycle
+	res := strings.Builder{}
+	for y := 0; y < d.CRT.Height; y++ {
+		for x := 0; x < d.CRT.Width; x++ {
+			switch {
+			case d.CRT.Pixels[y][x]:
+				res.WriteString("#")
+			case drawn < d.CRT.Width*d.CRT.Height && y*d.CRT.Width+x >= drawn:
+				res.WriteString(".")
+			default:
+				res.WriteString(" ")
+			}
+		}
+		res.WriteString("\n")
+	}
+	fmt.Fprint(d.out, res.String())
+}
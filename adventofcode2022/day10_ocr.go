@@ -0,0 +1,178 @@
+package adventofcode2022
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Framebuffer is a monochrome screen, lit pixels are true
+type Framebuffer struct {
+	Width  int
+	Height int
+	Pixels [][]bool
+}
+
+func NewFramebuffer(width int, height int) Framebuffer {
+	pixels := make([][]bool, height)
+	for i := range pixels {
+		pixels[i] = make([]bool, width)
+	}
+	return Framebuffer{
+		Width:  width,
+		Height: height,
+		Pixels: pixels,
+	}
+}
+
+// ParseFramebuffer reads ASCII art, '#' is a lit pixel, any other symbol is a dark one
+func ParseFramebuffer(lines []string) Framebuffer {
+	width := 0
+	for _, l := range lines {
+		width = Max(width, len(l))
+	}
+	fb := NewFramebuffer(width, len(lines))
+	for y, l := range lines {
+		for x, c := range l {
+			fb.Pixels[y][x] = c == '#'
+		}
+	}
+	return fb
+}
+
+func (fb Framebuffer) Lit(x int, y int) bool {
+	if x < 0 || y < 0 || x >= fb.Width || y >= fb.Height {
+		return false
+	}
+	return fb.Pixels[y][x]
+}
+
+// String renders framebuffer as ASCII art, like the puzzle does
+func (fb Framebuffer) String() string {
+	return fb.render(0, 0, fb.Width, fb.Height, "#", " ")
+}
+
+func (fb Framebuffer) render(fromX int, fromY int, w int, h int, lit string, dark string) string {
+	res := strings.Builder{}
+	for y := fromY; y < fromY+h; y++ {
+		for x := fromX; x < fromX+w; x++ {
+			if fb.Lit(x, y) {
+				res.WriteString(lit)
+			} else {
+				res.WriteString(dark)
+			}
+		}
+		res.WriteString("\n")
+	}
+	return res.String()
+}
+
+// Font describes fixed width letters, glyphs are keyed by bitmap rows joined with '\n' ('#' lit, '.' dark)
+type Font struct {
+	Width   int
+	Height  int
+	Spacing int
+	Glyphs  map[string]rune
+}
+
+// AoCFont6 is the 4x6 font used by 2016 day 8, 2019 day 8 and 11, 2021 day 13, 2022 day 10
+var AoCFont6 = Font{
+	Width:   4,
+	Height:  6,
+	Spacing: 1,
+	Glyphs: map[string]rune{
+		".##.\n#..#\n#..#\n####\n#..#\n#..#": 'A',
+		"###.\n#..#\n###.\n#..#\n#..#\n###.": 'B',
+		".##.\n#..#\n#...\n#...\n#..#\n.##.": 'C',
+		"####\n#...\n###.\n#...\n#...\n####": 'E',
+		"####\n#...\n###.\n#...\n#...\n#...": 'F',
+		".##.\n#..#\n#...\n#.##\n#..#\n.###": 'G',
+		"#..#\n#..#\n####\n#..#\n#..#\n#..#": 'H',
+		".###\n..#.\n..#.\n..#.\n..#.\n.###": 'I',
+		"..##\n...#\n...#\n...#\n#..#\n.##.": 'J',
+		"#..#\n#.#.\n##..\n#.#.\n#.#.\n#..#": 'K',
+		"#...\n#...\n#...\n#...\n#...\n####": 'L',
+		".##.\n#..#\n#..#\n#..#\n#..#\n.##.": 'O',
+		"###.\n#..#\n#..#\n###.\n#...\n#...": 'P',
+		"###.\n#..#\n#..#\n###.\n#.#.\n#..#": 'R',
+		".###\n#...\n#...\n.##.\n...#\n###.": 'S',
+		"#..#\n#..#\n#..#\n#..#\n#..#\n.##.": 'U',
+		"####\n...#\n..#.\n.#..\n#...\n####": 'Z',
+	},
+}
+
+// AoCFont10 is the 6x10 font used by 2018 day 10
+var AoCFont10 = Font{
+	Width:   6,
+	Height:  10,
+	Spacing: 2,
+	Glyphs: map[string]rune{
+		"..##..\n.#..#.\n#....#\n#....#\n#....#\n######\n#....#\n#....#\n#....#\n#....#": 'A',
+		"#####.\n#....#\n#....#\n#....#\n#####.\n#....#\n#....#\n#....#\n#....#\n#####.": 'B',
+		".####.\n#....#\n#.....\n#.....\n#.....\n#.....\n#.....\n#.....\n#....#\n.####.": 'C',
+		"######\n#.....\n#.....\n#.....\n#####.\n#.....\n#.....\n#.....\n#.....\n######": 'E',
+		"######\n#.....\n#.....\n#.....\n#####.\n#.....\n#.....\n#.....\n#.....\n#.....": 'F',
+		".####.\n#....#\n#.....\n#.....\n#.....\n#..###\n#....#\n#....#\n#...##\n.###.#": 'G',
+		"#....#\n#....#\n#....#\n#....#\n######\n#....#\n#....#\n#....#\n#....#\n#....#": 'H',
+		"...###\n....#.\n....#.\n....#.\n....#.\n....#.\n....#.\n#...#.\n#...#.\n.###..": 'J',
+		"#....#\n#...#.\n#..#..\n#.#...\n##....\n##....\n#.#...\n#..#..\n#...#.\n#....#": 'K',
+		"#.....\n#.....\n#.....\n#.....\n#.....\n#.....\n#.....\n#.....\n#.....\n######": 'L',
+		"#....#\n##...#\n##...#\n#.#..#\n#.#..#\n#..#.#\n#..#.#\n#...##\n#...##\n#....#": 'N',
+		"#####.\n#....#\n#....#\n#....#\n#####.\n#.....\n#.....\n#.....\n#.....\n#.....": 'P',
+		"#####.\n#....#\n#....#\n#....#\n#####.\n#..#..\n#...#.\n#...#.\n#....#\n#....#": 'R',
+		"#....#\n#....#\n.#..#.\n.#..#.\n..##..\n..##..\n.#..#.\n.#..#.\n#....#\n#....#": 'X',
+		"######\n.....#\n.....#\n....#.\n...#..\n..#...\n.#....\n#.....\n#.....\n######": 'Z',
+	},
+}
+
+// UnknownGlyphError is returned when a glyph cell doesn't match any letter of the font
+type UnknownGlyphError struct {
+	Position int
+	Bitmap   string
+}
+
+func (e *UnknownGlyphError) Error() string {
+	return fmt.Sprintf("unknown glyph at position %v:\n%v", e.Position, strings.ReplaceAll(e.Bitmap, ".", " "))
+}
+
+// FontFor picks font by framebuffer height
+func FontFor(fb Framebuffer) (Font, error) {
+	switch fb.Height {
+	case AoCFont6.Height:
+		return AoCFont6, nil
+	case AoCFont10.Height:
+		return AoCFont10, nil
+	default:
+		return Font{}, fmt.Errorf("expected framebuffer height %v or %v, got: %v", AoCFont6.Height, AoCFont10.Height, fb.Height)
+	}
+}
+
+// Recognize splits framebuffer into glyph cells of font width separated by font spacing
+// and maps every cell to a letter, empty cells are skipped
+func (f Font) Recognize(fb Framebuffer) (string, error) {
+	if fb.Height != f.Height {
+		return "", fmt.Errorf("expected framebuffer height %v, got: %v", f.Height, fb.Height)
+	}
+	res := strings.Builder{}
+	stride := f.Width + f.Spacing
+	for pos, x := 0, 0; x < fb.Width; pos, x = pos+1, x+stride {
+		bitmap := strings.TrimSuffix(fb.render(x, 0, f.Width, f.Height, "#", "."), "\n")
+		if !strings.Contains(bitmap, "#") {
+			continue
+		}
+		letter, ok := f.Glyphs[bitmap]
+		if !ok {
+			return "", &UnknownGlyphError{Position: pos, Bitmap: bitmap}
+		}
+		res.WriteRune(letter)
+	}
+	return res.String(), nil
+}
+
+// OCR recognizes letters on framebuffer using the font matching its height
+func OCR(fb Framebuffer) (string, error) {
+	font, err := FontFor(fb)
+	if err != nil {
+		return "", err
+	}
+	return font.Recognize(fb)
+}
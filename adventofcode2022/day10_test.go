@@ -0,0 +1,236 @@
+package adventofcode2022_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTask10_2(t *testing.T) {
+	res, err := adventofcode2022.Task10_2(
+		&adventofcode2022.FileToStringsInputReader{Path: "../adventofcode2022/day10.data"},
+		adventofcode2022.ToProgram,
+		false,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "Result: PGHFGLUG", res)
+}
+
+func TestOCR(t *testing.T) {
+	tt := []struct {
+		name     string
+		lines    []string
+		expected string
+	}{
+		{
+			"small font",
+			[]string{
+				"#  #  ##   ##  ###  ",
+				"#  # #  # #  # #  # ",
+				"#### #  # #    #  # ",
+				"#  # #  # #    ###  ",
+				"#  # #  # #  # #    ",
+				"#  #  ##   ##  #    ",
+			},
+			"HOCP",
+		},
+		{
+			"big font",
+			[]string{
+				"#....#..######",
+				"#....#..#.....",
+				".#..#...#.....",
+				".#..#...#.....",
+				"..##....#####.",
+				"..##....#.....",
+				".#..#...#.....",
+				".#..#...#.....",
+				"#....#..#.....",
+				"#....#..######",
+			},
+			"XE",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := adventofcode2022.OCR(adventofcode2022.ParseFramebuffer(tc.lines))
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestOCRUnknownGlyph(t *testing.T) {
+	fb := adventofcode2022.ParseFramebuffer([]string{
+		"#  # ####",
+		"#  # #  #",
+		"#### #  #",
+		"#  # #  #",
+		"#  # #  #",
+		"#  # ####",
+	})
+	_, err := adventofcode2022.OCR(fb)
+	var unknown *adventofcode2022.UnknownGlyphError
+	assert.True(t, errors.As(err, &unknown))
+	assert.Equal(t, 1, unknown.Position)
+	assert.Equal(t, "####\n#..#\n#..#\n#..#\n#..#\n####", unknown.Bitmap)
+
+	// X is never changed, so every row starts with ### which isn't a letter
+	program := make(linesReader, 240)
+	for i := range program {
+		program[i] = "noop"
+	}
+	_, err = adventofcode2022.Task10_2(program, adventofcode2022.ToProgram, false)
+	assert.True(t, errors.As(err, &unknown))
+	assert.Contains(t, err.Error(), "CRT:\n###     ")
+}
+
+func TestTask10_1(t *testing.T) {
+	res, err := adventofcode2022.Task10_1(
+		&adventofcode2022.FileToStringsInputReader{Path: "../adventofcode2022/day10.data"},
+		adventofcode2022.ToProgram,
+		false,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "Result: 15260", res)
+}
+
+func TestTask10Loop(t *testing.T) {
+	loop := linesReader{"loop:", "addx 1", "jmp loop"}
+	_, err := adventofcode2022.Task10_1(loop, adventofcode2022.ToProgram, false)
+	assert.ErrorContains(t, err, "cycle limit")
+	_, err = adventofcode2022.Task10_2(loop, adventofcode2022.ToProgram, false)
+	assert.ErrorContains(t, err, "cycle limit")
+}
+
+func TestCPU(t *testing.T) {
+	src := []string{
+		"; factorial of N",
+		"set acc 1",
+		"loop:",
+		"  jz N, end",
+		"  mul acc N",
+		"  sub N 1",
+		"  jmp loop",
+		"end:",
+		"halt",
+		"set acc 0 # never executed",
+	}
+	program, err := adventofcode2022.Assemble(src, adventofcode2022.DefaultInstructionSet())
+	assert.Nil(t, err)
+
+	cpu := adventofcode2022.NewCPU(program, map[string]int{"N": 5, "acc": 0})
+	ticks := 0
+	cpu.OnTick(func(cycle int, cpu *adventofcode2022.CPU) {
+		ticks++
+		assert.Equal(t, ticks, cycle)
+	})
+	assert.Nil(t, cpu.Run())
+	assert.Equal(t, 120, cpu.Registers["acc"])
+	// set + 5 iterations of 4 instructions + final jz + halt
+	assert.Equal(t, 1+5*4+1+1, ticks)
+	assert.ErrorIs(t, cpu.Tick(), adventofcode2022.ErrHalted)
+}
+
+func TestCPUErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		src  []string
+	}{
+		{"negative jump", []string{"jmp -1"}},
+		{"jump after the end", []string{"jnz 1 +3", "noop"}},
+		{"unknown register read", []string{"add X Y"}},
+		{"unknown register write", []string{"set Y 1"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			program, err := adventofcode2022.Assemble(tc.src, adventofcode2022.DefaultInstructionSet())
+			assert.Nil(t, err)
+			cpu := adventofcode2022.NewCPU(program, map[string]int{"X": 1})
+			err = cpu.Run()
+			assert.NotNil(t, err)
+			assert.NotErrorIs(t, err, adventofcode2022.ErrHalted)
+		})
+	}
+}
+
+func TestCPUCustomInstruction(t *testing.T) {
+	is := adventofcode2022.DefaultInstructionSet()
+	is.Add(adventofcode2022.InstructionDef{
+		Name:   "dbl",
+		Cycles: 3,
+		Args:   []adventofcode2022.ArgKind{adventofcode2022.RegArg},
+		Exec: func(cpu *adventofcode2022.CPU, args []adventofcode2022.Operand) error {
+			cpu.Registers[args[0].Reg] *= 2
+			return nil
+		},
+	})
+	program, err := adventofcode2022.Assemble([]string{"dbl X", "addx 3", "jmp -2"}, is)
+	assert.Nil(t, err)
+
+	cpu := adventofcode2022.NewCPU(program, map[string]int{"X": 1})
+	during := []int{}
+	cpu.OnTick(func(cycle int, cpu *adventofcode2022.CPU) {
+		during = append(during, cpu.Registers["X"])
+	})
+	cpu.MaxCycles = 6
+	assert.NotNil(t, cpu.Run())
+	assert.Equal(t, []int{1, 1, 1, 2, 2, 5}, during)
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		src  []string
+	}{
+		{"unknown instruction", []string{"push 1"}},
+		{"wrong args count", []string{"addx 1 2"}},
+		{"unknown label", []string{"jmp nowhere"}},
+		{"duplicate label", []string{"a:", "noop", "a:"}},
+		{"register expected", []string{"add 1 X"}},
+		{"bad value", []string{"addx 1x"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := adventofcode2022.Assemble(tc.src, adventofcode2022.DefaultInstructionSet())
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestDebugger(t *testing.T) {
+	program, err := adventofcode2022.ToProgram(
+		&adventofcode2022.FileToStringsInputReader{Path: "../adventofcode2022/day10.data"},
+	)
+	assert.Nil(t, err)
+
+	out := bytes.Buffer{}
+	in := strings.NewReader("watch X\nbreak cycle 220\ncontinue\nstep\nbreak X == 100\ncontinue\nquit\n")
+	d := adventofcode2022.NewDebugger(program, in, &out)
+	assert.Nil(t, d.Run())
+
+	assert.Contains(t, out.String(), "breakpoint #1 cycle 220")
+	assert.Contains(t, out.String(), "program finished")
+	assert.True(t, d.CPU.Halted())
+}
+
+func TestDebuggerCycleLimit(t *testing.T) {
+	program, err := adventofcode2022.Assemble([]string{"loop:", "addx 1", "jmp loop"}, adventofcode2022.DefaultInstructionSet())
+	assert.Nil(t, err)
+
+	out := bytes.Buffer{}
+	d := adventofcode2022.NewDebugger(program, strings.NewReader("continue\ncontinue\nquit\n"), &out)
+	d.MaxCycles = 100
+	assert.Nil(t, d.Run())
+
+	assert.Contains(t, out.String(), "stopped, cycle limit 100 reached\ncycle: 100, pc: 0, line 2: addx 1 (1/2)")
+	assert.Contains(t, out.String(), "cycle: 200, pc: 1, line 3: jmp @0 (0/1)")
+	assert.False(t, d.CPU.Halted())
+}
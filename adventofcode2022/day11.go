@@ -1,6 +1,7 @@
 package adventofcode2022
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -16,50 +17,12 @@ type Monkey struct {
 	Inspected int
 }
 
-type OpArgType int
-
-const (
-	Old OpArgType = iota
-	Custom
-)
-
-type OpArg struct {
-	Type  OpArgType
-	Value int
-}
-
 type Operation struct {
-	Arg1 OpArg
-	Arg2 OpArg
-	Func func(arg1 int, arg2 int) int
-}
-
-func (op Operation) Execute(value int) int {
-	var arg1 int
-	var arg2 int
-
-	if op.Arg1.Type == Custom {
-		arg1 = op.Arg1.Value
-	} else {
-		arg1 = value
-	}
-
-	if op.Arg2.Type == Custom {
-		arg2 = op.Arg2.Value
-	} else {
-		arg2 = value
-	}
-
-	return op.Func(arg1, arg2)
-
+	Expr Expr
 }
 
-func Addition(arg1 int, arg2 int) int {
-	return arg1 + arg2
-}
-
-func Multiplaction(arg1 int, arg2 int) int {
-	return arg1 * arg2
+func (op Operation) Execute(value int) (int, error) {
+	return op.Expr.Eval(value)
 }
 
 type Monkeys map[int]Monkey
@@ -105,44 +68,15 @@ func ToMonkeys(ir InputReader) (Monkeys, error) {
 
 		// parsing operation
 
-		opItms := strings.Split(lines[i+2], "=")
-		if len(opItms) == 0 || len(opItms) > 2 {
+		opItms := strings.SplitN(lines[i+2], "=", 2)
+		if len(opItms) != 2 || strings.TrimSpace(opItms[0]) != "Operation: new" {
 			return nil, fmt.Errorf("expected format: [Operation: new = old * 19], got: [%v]", lines[i+2])
 		}
-		opItm := strings.TrimSpace(opItms[1])
-		opItmSplitted := strings.Split(opItm, " ")
-		if len(opItmSplitted) != 3 {
-			return nil, fmt.Errorf("expected format: [old * 19], got: [%v]", opItm)
-		}
-		op := Operation{}
-		switch opItmSplitted[0] {
-		case "old":
-			op.Arg1 = OpArg{Type: Old}
-		default:
-			i, err := strconv.Atoi(opItmSplitted[0])
-			if err != nil {
-				return nil, fmt.Errorf("expected format: [old * 19], got: [%v]", opItmSplitted[0])
-			}
-			op.Arg1 = OpArg{Type: Custom, Value: i}
-		}
-		switch opItmSplitted[1] {
-		case "+":
-			op.Func = Addition
-		case "*":
-			op.Func = Multiplaction
-		default:
-			return nil, fmt.Errorf("unsupported op: %v", opItmSplitted[1])
-		}
-		switch opItmSplitted[2] {
-		case "old":
-			op.Arg2 = OpArg{Type: Old}
-		default:
-			i, err := strconv.Atoi(opItmSplitted[2])
-			if err != nil {
-				return nil, fmt.Errorf("expected format: [old * 19], got: [%v]", opItmSplitted[2])
-			}
-			op.Arg2 = OpArg{Type: Custom, Value: i}
+		expr, err := ParseExpr(opItms[1])
+		if err != nil {
+			return nil, fmt.Errorf("monkey %v: %w", idx, err)
 		}
+		op := Operation{Expr: expr}
 
 		// parse condition
 
@@ -189,20 +123,28 @@ func ToMonkeys(ir InputReader) (Monkeys, error) {
 	return res, nil
 }
 
-func getLcm(m Monkeys) int {
-	lcm := 1
+// getLcm returns lcm of divisors of all monkeys, errLcmOverflow is returned if it doesn't fit into int
+func getLcm(m Monkeys) (int, error) {
+	res := 1
 	for _, v := range m {
-		lcm *= v.Divisor
+		var err error
+		if res, err = lcm(res, v.Divisor); err != nil {
+			return 0, err
+		}
 	}
-	return lcm
+	return res, nil
 }
 
-func inspect(monkeys Monkeys, lcm int, relief func(int) int) error {
+// inspect plays a single round, obs is optional and may be nil
+func inspect(monkeys Monkeys, lcm int, relief func(int) int, obs InspectObserver) error {
 	for i := 0; i < len(monkeys); i++ {
 		curr := monkeys[i]
 		for len(curr.Items) > 0 {
 			curr.Inspected += 1
-			tmpWorry := curr.Operation.Execute(curr.Items[0])
+			tmpWorry, err := curr.Operation.Execute(curr.Items[0])
+			if err != nil {
+				return err
+			}
 			tmpWorry %= lcm
 			tmpWorry = relief(tmpWorry)
 			if tmpWorry%curr.Divisor == 0 {
@@ -212,6 +154,9 @@ func inspect(monkeys Monkeys, lcm int, relief func(int) int) error {
 				}
 				throwTo.Items = append(throwTo.Items, tmpWorry)
 				monkeys[curr.IfTrue] = throwTo
+				if obs != nil {
+					obs.OnThrow(i, curr.IfTrue, tmpWorry)
+				}
 			} else {
 				throwTo, ok := monkeys[curr.IfFalse]
 				if !ok {
@@ -219,11 +164,17 @@ func inspect(monkeys Monkeys, lcm int, relief func(int) int) error {
 				}
 				throwTo.Items = append(throwTo.Items, tmpWorry)
 				monkeys[curr.IfFalse] = throwTo
+				if obs != nil {
+					obs.OnThrow(i, curr.IfFalse, tmpWorry)
+				}
 			}
 			curr.Items = curr.Items[1:]
 		}
 		monkeys[i] = curr
 	}
+	if obs != nil {
+		obs.OnRoundEnd(monkeys)
+	}
 	return nil
 }
 
@@ -233,13 +184,38 @@ func Task11_1(ir InputReader, cnvrtInpt func(InputReader) (Monkeys, error), debu
 		return "", err
 	}
 
-	lcm := getLcm(monkeys)
-	rounds := 20
-	for r := 0; r < rounds; r++ {
-		err := inspect(monkeys, lcm, func(v int) int { return v / 3 })
+	if debug {
+		report, err := VerifyModularShortcut(monkeys, 20, 3)
 		if err != nil {
 			return "", err
 		}
+		fmt.Printf("modulus verification: %v\n", report)
+	}
+
+	var obs InspectObserver
+	var trace *InspectTrace
+	if debug {
+		trace = NewInspectTrace()
+		obs = trace
+	}
+
+	rounds := 20
+	lcm, err := getLcm(monkeys)
+	switch {
+	case errors.Is(err, errLcmOverflow):
+		// there are only 20 rounds and worry levels are divided by 3, so big mode is fast enough
+		monkeys, err = SimulateBig(monkeys, rounds, 3)
+	case err == nil:
+		for r := 0; r < rounds && err == nil; r++ {
+			err = inspect(monkeys, lcm, func(v int) int { return v / 3 }, obs)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if debug {
+		debugD11Trace(trace)
 	}
 
 	max1 := 0
@@ -264,15 +240,38 @@ func Task11_2(ir InputReader, cnvrtInpt func(InputReader) (Monkeys, error), debu
 		return "", err
 	}
 
-	lcm := getLcm(monkeys)
+	if debug {
+		report, err := VerifyModularShortcut(monkeys, 20, 1)
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("modulus verification: %v\n", report)
+	}
+
+	var obs InspectObserver
+	var trace *InspectTrace
+	if debug {
+		trace = NewInspectTrace()
+		obs = trace
+	}
+
 	rounds := 10000
+	lcm, err := getLcm(monkeys)
+	if err != nil {
+		// without reduction worry levels grow too fast even for big mode
+		return "", fmt.Errorf("%w, but it's too slow for %v rounds", err, rounds)
+	}
 	for r := 0; r < rounds; r++ {
-		err := inspect(monkeys, lcm, func(v int) int { return v })
+		err := inspect(monkeys, lcm, func(v int) int { return v }, obs)
 		if err != nil {
 			return "", err
 		}
 	}
 
+	if debug {
+		debugD11Trace(trace)
+	}
+
 	max1 := int64(0)
 	max2 := int64(0)
 	for _, v := range monkeys {
@@ -0,0 +1,180 @@
+package adventofcode2022
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+func gcd(a int, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return Abs(a)
+}
+
+var errLcmOverflow = fmt.Errorf("lcm of divisors doesn't fit into int, worry levels can be kept only in big mode")
+
+func lcm(a int, b int) (int, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	x, y := Abs(a/gcd(a, b)), Abs(b)
+	if x > math.MaxInt/y {
+		return 0, fmt.Errorf("%w: lcm(%v, %v)", errLcmOverflow, a, b)
+	}
+	return x * y, nil
+}
+
+func divisorsProduct(m Monkeys) int {
+	res := 1
+	for _, v := range m {
+		res *= v.Divisor
+	}
+	return res
+}
+
+// bigMonkeys keeps worry levels without any modular reduction
+type bigMonkeys struct {
+	monkeys Monkeys
+	items   map[int][]*big.Int
+}
+
+func toBigMonkeys(m Monkeys) bigMonkeys {
+	res := bigMonkeys{
+		monkeys: copyMonkeys(m),
+		items:   map[int][]*big.Int{},
+	}
+	for k, v := range m {
+		items := make([]*big.Int, len(v.Items))
+		for i, item := range v.Items {
+			items[i] = big.NewInt(int64(item))
+		}
+		res.items[k] = items
+	}
+	return res
+}
+
+func copyMonkeys(m Monkeys) Monkeys {
+	res := Monkeys{}
+	for k, v := range m {
+		v.Items = append([]int{}, v.Items...)
+		res[k] = v
+	}
+	return res
+}
+
+// inspectBig is the same round as inspect but uses math/big and divides worry level by relief after every inspection,
+// relief 1 means no relief at all
+func inspectBig(bm bigMonkeys, relief int) error {
+	bigRelief := big.NewInt(int64(relief))
+	rem := new(big.Int)
+	for i := 0; i < len(bm.monkeys); i++ {
+		curr := bm.monkeys[i]
+		divisor := big.NewInt(int64(curr.Divisor))
+		for _, item := range bm.items[i] {
+			curr.Inspected += 1
+			worry, err := curr.Operation.Expr.EvalBig(item)
+			if err != nil {
+				return err
+			}
+			worry.Quo(worry, bigRelief)
+			throwTo := curr.IfFalse
+			if rem.Rem(worry, divisor).Sign() == 0 {
+				throwTo = curr.IfTrue
+			}
+			if _, ok := bm.monkeys[throwTo]; !ok {
+				return fmt.Errorf("monkey: [%v] not exists", throwTo)
+			}
+			bm.items[throwTo] = append(bm.items[throwTo], worry)
+		}
+		bm.items[i] = nil
+		bm.monkeys[i] = curr
+	}
+	return nil
+}
+
+// SimulateBig runs rounds on a copy of monkeys using arbitrary precision worry levels,
+// returned monkeys have inspection counts only, items aren't kept
+func SimulateBig(m Monkeys, rounds int, relief int) (Monkeys, error) {
+	if relief < 1 {
+		return nil, fmt.Errorf("expected relief >= 1, got: %v", relief)
+	}
+	bm := toBigMonkeys(m)
+	for r := 0; r < rounds; r++ {
+		if err := inspectBig(bm, relief); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range bm.monkeys {
+		v.Items = nil
+		bm.monkeys[k] = v
+	}
+	return bm.monkeys, nil
+}
+
+// ModulusReport describes whether reducing worry levels by lcm of divisors gives the same result
+// as arbitrary precision simulation
+type ModulusReport struct {
+	Lcm             int
+	DivisorsProduct int
+	Coprime         bool
+	Rounds          int
+	// first round (1-based) where inspection counts diverged, 0 if there is no divergence
+	MismatchRound int
+	// error of the int path, like overflow, if it happened
+	IntErr error
+}
+
+func (r ModulusReport) Sound() bool {
+	return r.MismatchRound == 0 && r.IntErr == nil
+}
+
+func (r ModulusReport) String() string {
+	res := fmt.Sprintf("lcm: %v, divisors product: %v, coprime: %v, rounds: %v", r.Lcm, r.DivisorsProduct, r.Coprime, r.Rounds)
+	if r.IntErr != nil {
+		return fmt.Sprintf("%v, int path failed: %v", res, r.IntErr)
+	}
+	if r.MismatchRound != 0 {
+		return fmt.Sprintf("%v, inspections diverged on round: %v", res, r.MismatchRound)
+	}
+	return fmt.Sprintf("%v, modular shortcut is sound", res)
+}
+
+// VerifyModularShortcut runs the int path reduced by lcm and the math/big path without reduction side by side
+// and compares inspection counts after every round.
+// Worry levels grow fast without reduction, so only a limited number of rounds is practical
+func VerifyModularShortcut(m Monkeys, rounds int, relief int) (ModulusReport, error) {
+	if relief < 1 {
+		return ModulusReport{}, fmt.Errorf("expected relief >= 1, got: %v", relief)
+	}
+	report := ModulusReport{
+		DivisorsProduct: divisorsProduct(m),
+		Rounds:          rounds,
+	}
+	var err error
+	if report.Lcm, err = getLcm(m); err != nil {
+		report.IntErr = err
+		return report, nil
+	}
+	report.Coprime = report.Lcm == report.DivisorsProduct
+
+	intMonkeys := copyMonkeys(m)
+	bm := toBigMonkeys(m)
+	for r := 1; r <= rounds; r++ {
+		if err := inspectBig(bm, relief); err != nil {
+			return ModulusReport{}, err
+		}
+		if err := inspect(intMonkeys, report.Lcm, func(v int) int { return v / relief }, nil); err != nil {
+			report.IntErr = err
+			return report, nil
+		}
+		for k, v := range intMonkeys {
+			if bm.monkeys[k].Inspected != v.Inspected {
+				report.MismatchRound = r
+				return report, nil
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,319 @@
+package adventofcode2022
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Expr is a node of monkey operation expression, like: old * (old + 3) % 7
+type Expr interface {
+	Eval(old int) (int, error)
+	EvalBig(old *big.Int) (*big.Int, error)
+	String() string
+}
+
+// OverflowError is returned when result of int operation doesn't fit into int
+type OverflowError struct {
+	Expr Expr
+	Old  int
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("integer overflow in: [%v], old: %v", e.Expr, e.Old)
+}
+
+type NumExpr struct {
+	Value int
+}
+
+func (e NumExpr) Eval(old int) (int, error) {
+	return e.Value, nil
+}
+
+func (e NumExpr) EvalBig(old *big.Int) (*big.Int, error) {
+	return big.NewInt(int64(e.Value)), nil
+}
+
+func (e NumExpr) String() string {
+	return strconv.Itoa(e.Value)
+}
+
+type OldExpr struct{}
+
+func (e OldExpr) Eval(old int) (int, error) {
+	return old, nil
+}
+
+func (e OldExpr) EvalBig(old *big.Int) (*big.Int, error) {
+	return new(big.Int).Set(old), nil
+}
+
+func (e OldExpr) String() string {
+	return "old"
+}
+
+type NegExpr struct {
+	Arg Expr
+}
+
+func (e NegExpr) Eval(old int) (int, error) {
+	v, err := e.Arg.Eval(old)
+	if err != nil {
+		return 0, err
+	}
+	if v == math.MinInt {
+		return 0, &OverflowError{Expr: e, Old: old}
+	}
+	return -v, nil
+}
+
+func (e NegExpr) EvalBig(old *big.Int) (*big.Int, error) {
+	v, err := e.Arg.EvalBig(old)
+	if err != nil {
+		return nil, err
+	}
+	return v.Neg(v), nil
+}
+
+func (e NegExpr) String() string {
+	return fmt.Sprintf("-%v", e.Arg)
+}
+
+type BinaryExpr struct {
+	Op    byte
+	Left  Expr
+	Right Expr
+}
+
+func (e BinaryExpr) Eval(old int) (int, error) {
+	l, err := e.Left.Eval(old)
+	if err != nil {
+		return 0, err
+	}
+	r, err := e.Right.Eval(old)
+	if err != nil {
+		return 0, err
+	}
+	switch e.Op {
+	case '+':
+		if (r > 0 && l > math.MaxInt-r) || (r < 0 && l < math.MinInt-r) {
+			return 0, &OverflowError{Expr: e, Old: old}
+		}
+		return l + r, nil
+	case '-':
+		if (r < 0 && l > math.MaxInt+r) || (r > 0 && l < math.MinInt+r) {
+			return 0, &OverflowError{Expr: e, Old: old}
+		}
+		return l - r, nil
+	case '*':
+		res := l * r
+		if l != 0 && (res/l != r || (l == -1 && r == math.MinInt)) {
+			return 0, &OverflowError{Expr: e, Old: old}
+		}
+		return res, nil
+	case '/', '%':
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero in: [%v], old: %v", e, old)
+		}
+		if l == math.MinInt && r == -1 {
+			return 0, &OverflowError{Expr: e, Old: old}
+		}
+		if e.Op == '/' {
+			return l / r, nil
+		}
+		return l % r, nil
+	default:
+		return 0, fmt.Errorf("unsupported op: %c", e.Op)
+	}
+}
+
+func (e BinaryExpr) EvalBig(old *big.Int) (*big.Int, error) {
+	l, err := e.Left.EvalBig(old)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.Right.EvalBig(old)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Op {
+	case '+':
+		return l.Add(l, r), nil
+	case '-':
+		return l.Sub(l, r), nil
+	case '*':
+		return l.Mul(l, r), nil
+	case '/', '%':
+		if r.Sign() == 0 {
+			return nil, fmt.Errorf("division by zero in: [%v], old: %v", e, old)
+		}
+		// Quo and Rem truncate like int operations do
+		if e.Op == '/' {
+			return l.Quo(l, r), nil
+		}
+		return l.Rem(l, r), nil
+	default:
+		return nil, fmt.Errorf("unsupported op: %c", e.Op)
+	}
+}
+
+func (e BinaryExpr) String() string {
+	return fmt.Sprintf("(%v %c %v)", e.Left, e.Op, e.Right)
+}
+
+type exprToken struct {
+	val string
+	pos int
+}
+
+// ParseExpr parses right side of monkey operation, supported:
+// integers, old, + - * / %, unary minus and parentheses
+func ParseExpr(s string) (Expr, error) {
+	tokens, err := tokenizeExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("expected expression, got empty string")
+	}
+	p := exprParser{src: s, tokens: tokens}
+	e, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.cur < len(p.tokens) {
+		return nil, p.unexpected()
+	}
+	return e, nil
+}
+
+func tokenizeExpr(s string) ([]exprToken, error) {
+	tokens := []exprToken{}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.IndexByte("+-*/%()", c) >= 0:
+			tokens = append(tokens, exprToken{string(c), i})
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, exprToken{s[i:j], i})
+			i = j
+		case c >= 'a' && c <= 'z':
+			j := i
+			for j < len(s) && s[j] >= 'a' && s[j] <= 'z' {
+				j++
+			}
+			if s[i:j] != "old" {
+				return nil, fmt.Errorf("unsupported identifier: [%v] at position %v in: [%v]", s[i:j], i, s)
+			}
+			tokens = append(tokens, exprToken{s[i:j], i})
+			i = j
+		default:
+			return nil, fmt.Errorf("unsupported symbol: [%c] at position %v in: [%v]", c, i, s)
+		}
+	}
+	return tokens, nil
+}
+
+type exprParser struct {
+	src    string
+	tokens []exprToken
+	cur    int
+}
+
+func (p *exprParser) peek() string {
+	if p.cur < len(p.tokens) {
+		return p.tokens[p.cur].val
+	}
+	return ""
+}
+
+func (p *exprParser) unexpected() error {
+	if p.cur >= len(p.tokens) {
+		return fmt.Errorf("unexpected end of expression: [%v]", p.src)
+	}
+	t := p.tokens[p.cur]
+	return fmt.Errorf("unexpected token: [%v] at position %v in: [%v]", t.val, t.pos, p.src)
+}
+
+// sum := product (('+' | '-') product)*
+func (p *exprParser) parseSum() (Expr, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.peek()[0]
+		p.cur++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = BinaryExpr{Op: op, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// product := unary (('*' | '/' | '%') unary)*
+func (p *exprParser) parseProduct() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "*" || p.peek() == "/" || p.peek() == "%" {
+		op := p.peek()[0]
+		p.cur++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = BinaryExpr{Op: op, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// unary := '-' unary | 'old' | number | '(' sum ')'
+func (p *exprParser) parseUnary() (Expr, error) {
+	t := p.peek()
+	switch {
+	case t == "-":
+		p.cur++
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NegExpr{Arg: arg}, nil
+	case t == "old":
+		p.cur++
+		return OldExpr{}, nil
+	case t == "(":
+		p.cur++
+		e, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, p.unexpected()
+		}
+		p.cur++
+		return e, nil
+	case t != "" && t[0] >= '0' && t[0] <= '9':
+		v, err := strconv.Atoi(t)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse number: [%v] in: [%v]: %v", t, p.src, err)
+		}
+		p.cur++
+		return NumExpr{Value: v}, nil
+	default:
+		return nil, p.unexpected()
+	}
+}
@@ -0,0 +1,181 @@
+package adventofcode2022_test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpr(t *testing.T) {
+	tt := []struct {
+		name     string
+		expr     string
+		old      int
+		expected int
+	}{
+		{"multiply by const", "old * 19", 3, 57},
+		{"square", "old * old", 7, 49},
+		{"const first", "6 + old", 4, 10},
+		{"precedence", "old + 2 * 3", 1, 7},
+		{"parentheses", "(old + 2) * 3", 1, 9},
+		{"multiple terms", "old * old - old / 2 % 3", 10, 98},
+		{"unary minus", "-old + 20", 5, 15},
+		{"nested parentheses", "((old))*(2+(1))", 5, 15},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := adventofcode2022.ParseExpr(tc.expr)
+			assert.Nil(t, err)
+			res, err := e.Eval(tc.old)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		expr string
+	}{
+		{"empty", "  "},
+		{"unknown identifier", "new * 2"},
+		{"unknown symbol", "old ^ 2"},
+		{"unclosed parenthesis", "(old + 2"},
+		{"unopened parenthesis", "old + 2)"},
+		{"missing operand", "old *"},
+		{"two operands", "old 2"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := adventofcode2022.ParseExpr(tc.expr)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestExprDivisionByZero(t *testing.T) {
+	e, err := adventofcode2022.ParseExpr("7 / old")
+	assert.Nil(t, err)
+	_, err = e.Eval(0)
+	assert.NotNil(t, err)
+}
+
+func TestVerifyModularShortcut(t *testing.T) {
+	monkeys, err := adventofcode2022.ToMonkeys(
+		&adventofcode2022.FileToStringsInputReader{Path: "../adventofcode2022/day11.data"},
+	)
+	assert.Nil(t, err)
+
+	report, err := adventofcode2022.VerifyModularShortcut(monkeys, 12, 1)
+	assert.Nil(t, err)
+	assert.True(t, report.Sound(), report.String())
+	assert.True(t, report.Coprime)
+	assert.Equal(t, report.DivisorsProduct, report.Lcm)
+}
+
+func TestExprOverflow(t *testing.T) {
+	e, err := adventofcode2022.ParseExpr("old * old")
+	assert.Nil(t, err)
+	_, err = e.Eval(1 << 32)
+	var overflow *adventofcode2022.OverflowError
+	assert.ErrorAs(t, err, &overflow)
+}
+
+var d11Example = []string{
+	"Monkey 0:",
+	"  Starting items: 79, 98",
+	"  Operation: new = old * 19",
+	"  Test: divisible by 23",
+	"    If true: throw to monkey 2",
+	"    If false: throw to monkey 3",
+	"",
+	"Monkey 1:",
+	"  Starting items: 54, 65, 75, 74",
+	"  Operation: new = old + 6",
+	"  Test: divisible by 19",
+	"    If true: throw to monkey 2",
+	"    If false: throw to monkey 0",
+	"",
+	"Monkey 2:",
+	"  Starting items: 79, 60, 97",
+	"  Operation: new = old * old",
+	"  Test: divisible by 13",
+	"    If true: throw to monkey 1",
+	"    If false: throw to monkey 3",
+	"",
+	"Monkey 3:",
+	"  Starting items: 74",
+	"  Operation: new = old + 3",
+	"  Test: divisible by 17",
+	"    If true: throw to monkey 0",
+	"    If false: throw to monkey 1",
+}
+
+func inspections(m adventofcode2022.Monkeys) []int {
+	res := make([]int, len(m))
+	for k, v := range m {
+		res[k] = v.Inspected
+	}
+	return res
+}
+
+func TestSimulateBig(t *testing.T) {
+	monkeys, err := adventofcode2022.ToMonkeys(linesReader(d11Example))
+	assert.Nil(t, err)
+
+	// the int path reduced by lcm gives the same inspections
+	res, err := adventofcode2022.Task11_1(linesReader(d11Example), adventofcode2022.ToMonkeys, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "Result: 10605", res)
+	big, err := adventofcode2022.SimulateBig(monkeys, 20, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{101, 95, 7, 105}, inspections(big))
+
+	big, err = adventofcode2022.SimulateBig(monkeys, 20, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{99, 97, 8, 103}, inspections(big))
+	report, err := adventofcode2022.VerifyModularShortcut(monkeys, 20, 1)
+	assert.Nil(t, err)
+	assert.True(t, report.Sound(), report.String())
+
+	// original monkeys aren't modified
+	assert.Equal(t, []int{0, 0, 0, 0}, inspections(monkeys))
+	_, err = adventofcode2022.SimulateBig(monkeys, 20, 0)
+	assert.NotNil(t, err)
+}
+
+func TestLcmOverflow(t *testing.T) {
+	// lcm of these primes doesn't fit into int
+	input := strings.NewReplacer(
+		"divisible by 23", "divisible by 1000000007",
+		"divisible by 19", "divisible by 1000000009",
+		"divisible by 13", "divisible by 998244353",
+	).Replace(strings.Join(d11Example, "\n"))
+	lines := linesReader(strings.Split(input, "\n"))
+	monkeys, err := adventofcode2022.ToMonkeys(lines)
+	assert.Nil(t, err)
+
+	report, err := adventofcode2022.VerifyModularShortcut(monkeys, 20, 3)
+	assert.Nil(t, err)
+	assert.NotNil(t, report.IntErr)
+	assert.False(t, report.Sound())
+
+	// part 1 falls back to big mode
+	big, err := adventofcode2022.SimulateBig(monkeys, 20, 3)
+	assert.Nil(t, err)
+	counts := inspections(big)
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	res, err := adventofcode2022.Task11_1(lines, adventofcode2022.ToMonkeys, false)
+	assert.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf("Result: %v", counts[0]*counts[1]), res)
+
+	_, err = adventofcode2022.Task11_2(lines, adventofcode2022.ToMonkeys, false)
+	assert.NotNil(t, err)
+}
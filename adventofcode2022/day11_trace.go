@@ -0,0 +1,150 @@
+package adventofcode2022
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InspectObserver is notified by inspect about every throw and about the end of every round
+type InspectObserver interface {
+	OnThrow(from int, to int, worry int)
+	OnRoundEnd(monkeys Monkeys)
+}
+
+type MonkeyRoundState struct {
+	Round     int
+	Monkey    int
+	Items     []int
+	Inspected int
+	// number of items thrown by monkey during the round per target monkey
+	Throws map[int]int
+}
+
+// InspectTrace records state of every monkey after every round
+type InspectTrace struct {
+	States []MonkeyRoundState
+	// total number of throws between monkeys, key is [from, to]
+	Edges map[[2]int]int
+
+	round  int
+	throws map[int]map[int]int
+}
+
+func NewInspectTrace() *InspectTrace {
+	return &InspectTrace{
+		Edges:  map[[2]int]int{},
+		throws: map[int]map[int]int{},
+	}
+}
+
+func (t *InspectTrace) OnThrow(from int, to int, worry int) {
+	if t.throws[from] == nil {
+		t.throws[from] = map[int]int{}
+	}
+	t.throws[from][to]++
+	t.Edges[[2]int{from, to}]++
+}
+
+func (t *InspectTrace) OnRoundEnd(monkeys Monkeys) {
+	t.round++
+	for i := 0; i < len(monkeys); i++ {
+		throws := t.throws[i]
+		if throws == nil {
+			throws = map[int]int{}
+		}
+		t.States = append(t.States, MonkeyRoundState{
+			Round:     t.round,
+			Monkey:    i,
+			Items:     append([]int{}, monkeys[i].Items...),
+			Inspected: monkeys[i].Inspected,
+			Throws:    throws,
+		})
+	}
+	t.throws = map[int]map[int]int{}
+}
+
+// WriteCSV writes one row per monkey per round, items and throws are space separated,
+// throws are in format: to:count
+func (t *InspectTrace) WriteCSV(writer io.Writer) error {
+	w := csv.NewWriter(writer)
+	if err := w.Write([]string{"round", "monkey", "inspected", "items", "throws"}); err != nil {
+		return err
+	}
+	for _, s := range t.States {
+		items := make([]string, len(s.Items))
+		for i, item := range s.Items {
+			items[i] = strconv.Itoa(item)
+		}
+		targets := make([]int, 0, len(s.Throws))
+		for to := range s.Throws {
+			targets = append(targets, to)
+		}
+		sort.Ints(targets)
+		throws := make([]string, len(targets))
+		for i, to := range targets {
+			throws[i] = fmt.Sprintf("%v:%v", to, s.Throws[to])
+		}
+		err := w.Write([]string{
+			strconv.Itoa(s.Round),
+			strconv.Itoa(s.Monkey),
+			strconv.Itoa(s.Inspected),
+			strings.Join(items, " "),
+			strings.Join(throws, " "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// WriteDOT writes graphviz graph of throws, edge weight is total number of throws
+func (t *InspectTrace) WriteDOT(writer io.Writer) error {
+	edges := make([][2]int, 0, len(t.Edges))
+	for e := range t.Edges {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] == edges[j][0] {
+			return edges[i][1] < edges[j][1]
+		}
+		return edges[i][0] < edges[j][0]
+	})
+
+	str := strings.Builder{}
+	str.WriteString("digraph monkeys {\n")
+	for _, e := range edges {
+		str.WriteString(fmt.Sprintf("\t\"%v\" -> \"%v\" [label=\"%v\", weight=%v];\n", e[0], e[1], t.Edges[e], t.Edges[e]))
+	}
+	str.WriteString("}\n")
+	_, err := io.WriteString(writer, str.String())
+	return err
+}
+
+func debugD11Trace(trace *InspectTrace) {
+	f, err := os.Create("debug_11d_trace.csv")
+	if err != nil {
+		fmt.Printf("can't print debug: %v\n", err)
+	} else {
+		defer f.Close()
+		if err := trace.WriteCSV(f); err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+		}
+	}
+
+	f, err = os.Create("debug_11d_throws.dot")
+	if err != nil {
+		fmt.Printf("can't print debug: %v\n", err)
+	} else {
+		defer f.Close()
+		if err := trace.WriteDOT(f); err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package adventofcode2022
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspectTrace(t *testing.T) {
+	monkeys, err := ToMonkeys(&FileToStringsInputReader{Path: "../adventofcode2022/day11.data"})
+	assert.Nil(t, err)
+
+	trace := NewInspectTrace()
+	lcm, err := getLcm(monkeys)
+	assert.Nil(t, err)
+	rounds := 20
+	for r := 0; r < rounds; r++ {
+		assert.Nil(t, inspect(monkeys, lcm, func(v int) int { return v / 3 }, trace))
+	}
+
+	assert.Len(t, trace.States, rounds*len(monkeys))
+
+	inspected := 0
+	for _, m := range monkeys {
+		inspected += m.Inspected
+	}
+	throws := 0
+	for _, c := range trace.Edges {
+		throws += c
+	}
+	assert.Equal(t, inspected, throws)
+
+	last := trace.States[len(trace.States)-len(monkeys):]
+	for _, s := range last {
+		assert.Equal(t, rounds, s.Round)
+		assert.Equal(t, monkeys[s.Monkey].Inspected, s.Inspected)
+	}
+
+	csvBuf := bytes.Buffer{}
+	assert.Nil(t, trace.WriteCSV(&csvBuf))
+	assert.Equal(t, rounds*len(monkeys)+1, strings.Count(csvBuf.String(), "\n"))
+
+	dotBuf := bytes.Buffer{}
+	assert.Nil(t, trace.WriteDOT(&dotBuf))
+	assert.True(t, strings.HasPrefix(dotBuf.String(), "digraph monkeys {"))
+	assert.Equal(t, len(trace.Edges), strings.Count(dotBuf.String(), "->"))
+}
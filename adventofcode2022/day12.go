@@ -5,11 +5,9 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
-	"io"
 	"math"
 	"math/rand"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/faiface/pixel"
@@ -103,51 +101,25 @@ func bfsIter(graphMatrix [][]int, visited [][]int, start Point, finish Point, qu
 	return false
 }
 
-func bfs(graphMatrix [][]int, start Point, finish Point) [][]int {
-	visited := [][]int{}
-	for i := 0; i < len(graphMatrix); i++ {
-		visited = append(visited, make([]int, len(graphMatrix[0])))
-	}
-
-	queue := []Point{}
-
-	queue = append(queue, Point{X: start.X, Y: start.Y})
-	visited[start.Y][start.X] = 0
-	var finished bool
-
-	for len(queue) > 0 {
-		finished = bfsIter(graphMatrix, visited, start, finish, &queue)
-		if finished {
-			break
-		}
-	}
-	return visited
-}
-
 func Task12_1(ir InputReader, cnvrtInpt func(ir InputReader) (ElevationMap, error), debug bool) (string, error) {
 	data, err := cnvrtInpt(ir)
 	if err != nil {
 		return "", err
 	}
 
-	visited := bfs(data.Map, data.Start, data.Finish)
+	res, err := BFSFinder{}.FindPath(data, data.Start, data.Finish, PuzzleRules)
+	if err != nil {
+		return "", err
+	}
+	if !res.Found() {
+		return "", fmt.Errorf("finish %v isn't reachable from start %v", data.Finish, data.Start)
+	}
 
 	if debug {
-		f, err := os.Create("debug_12d_letters.debug")
-		if err != nil {
-			fmt.Printf("can't print debug: %v\n", err)
-		} else {
-			debugShowLetters(data.Map, visited, f)
-		}
-		f, err = os.Create("debug_12d_path.debug")
-		if err != nil {
-			fmt.Printf("can't print debug: %v\n", err)
-		} else {
-			debugShowPathL(visited, f)
-		}
+		debugD12Route(data, data.Start, res.Path)
 	}
 
-	return fmt.Sprintf("Result: %v", visited[data.Finish.Y][data.Finish.X]), nil
+	return fmt.Sprintf("Result: %v", res.Steps()), nil
 }
 
 func Task12_2(ir InputReader, cnvrtInpt func(ir InputReader) (ElevationMap, error), debug bool) (string, error) {
@@ -156,71 +128,53 @@ func Task12_2(ir InputReader, cnvrtInpt func(ir InputReader) (ElevationMap, erro
 		return "", err
 	}
 
-	var minVisited [][]int
-
-	minPath := math.MaxInt32
+	var best PathResult
+	var bestStart Point
 	for i := 0; i < len(data.Map); i++ {
 		for j := 0; j < len(data.Map[i]); j++ {
 			if ((i == 0 || i == len(data.Map)-1) && data.Map[i][j] == 'a') ||
 				((j == 0 || j == len(data.Map[i])-1) && data.Map[i][j] == 'a') {
-				currVisited := bfs(data.Map, Point{X: j, Y: i}, data.Finish)
-				currPath := currVisited[data.Finish.Y][data.Finish.X]
-				if currPath != 0 {
-					if currPath < minPath {
-						minPath = currPath
-						minVisited = currVisited
-					}
+				start := Point{X: j, Y: i}
+				res, err := BFSFinder{}.FindPath(data, start, data.Finish, PuzzleRules)
+				if err != nil {
+					return "", err
+				}
+				if res.Found() && (!best.Found() || res.Steps() < best.Steps()) {
+					best, bestStart = res, start
 				}
 			}
 		}
 	}
+	if !best.Found() {
+		return "", fmt.Errorf("finish %v isn't reachable from any start", data.Finish)
+	}
 
 	if debug {
-		f, err := os.Create("debug_12d_letters.debug")
-		if err != nil {
-			fmt.Printf("can't print debug: %v\n", err)
-		} else {
-			debugShowLetters(data.Map, minVisited, f)
-		}
-		f, err = os.Create("debug_12d_path.debug")
-		if err != nil {
-			fmt.Printf("can't print debug: %v\n", err)
-		} else {
-			debugShowPathL(minVisited, f)
-		}
+		debugD12Route(data, bestStart, best.Path)
 	}
 
-	return fmt.Sprintf("Result: %v", minPath), nil
+	return fmt.Sprintf("Result: %v", best.Steps()), nil
 }
 
-func debugShowLetters(letters [][]int, visited [][]int, writer io.Writer) {
-	builder := strings.Builder{}
-	for i := 0; i < len(visited); i++ {
-		for j := 0; j < len(visited[i]); j++ {
-			if visited[i][j] == 0 {
-				builder.WriteString(".")
-			} else {
-				builder.WriteString(fmt.Sprintf("%v", string(rune(letters[i][j]))))
-			}
+// debugD12Route writes the route and compares all algorithms on the same start
+func debugD12Route(data ElevationMap, start Point, path []Point) {
+	for _, name := range []string{"bfs", "dijkstra", "astar"} {
+		res, err := PathFinders[name].FindPath(data, start, data.Finish, PuzzleRules)
+		if err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+			return
 		}
-		builder.WriteString("\n")
+		fmt.Printf("%-8v steps: %v, explored: %v\n", name, res.Steps(), res.Explored)
 	}
-	writer.Write([]byte(builder.String()))
-}
-
-func debugShowPathL(visited [][]int, writer io.Writer) {
-	builder := strings.Builder{}
-	for i := 0; i < len(visited); i++ {
-		for j := 0; j < len(visited[i]); j++ {
-			if visited[i][j] == 0 {
-				builder.WriteString(" .. ")
-			} else {
-				builder.WriteString(fmt.Sprintf("%3d ", visited[i][j]))
-			}
-		}
-		builder.WriteString("\n")
+	f, err := os.Create("debug_12d_path.debug")
+	if err != nil {
+		fmt.Printf("can't print debug: %v\n", err)
+		return
+	}
+	defer f.Close()
+	if err := WriteRoute(f, data, path); err != nil {
+		fmt.Printf("can't print debug: %v\n", err)
 	}
-	writer.Write([]byte(builder.String()))
 }
 
 var threshold = 98
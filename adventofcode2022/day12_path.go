@@ -0,0 +1,233 @@
+package adventofcode2022
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ClimbRules limit the difference of elevations of a single step, negative value means no limit
+type ClimbRules struct {
+	MaxAscent  int
+	MaxDescent int
+}
+
+// PuzzleRules allow to climb at most one level up and to jump down from any height
+var PuzzleRules = ClimbRules{MaxAscent: 1, MaxDescent: -1}
+
+func (r ClimbRules) CanStep(from int, to int) bool {
+	if to > from {
+		return r.MaxAscent < 0 || to-from <= r.MaxAscent
+	}
+	return r.MaxDescent < 0 || from-to <= r.MaxDescent
+}
+
+func (m ElevationMap) Contains(p Point) bool {
+	return p.Y >= 0 && p.Y < len(m.Map) && p.X >= 0 && p.X < len(m.Map[p.Y])
+}
+
+func (m ElevationMap) Elevation(p Point) int {
+	return m.Map[p.Y][p.X]
+}
+
+// Neighbours returns points reachable from p by a single step: down, right, up, left
+func (m ElevationMap) Neighbours(p Point, rules ClimbRules) []Point {
+	res := make([]Point, 0, 4)
+	for _, n := range []Point{{X: p.X, Y: p.Y + 1}, {X: p.X + 1, Y: p.Y}, {X: p.X, Y: p.Y - 1}, {X: p.X - 1, Y: p.Y}} {
+		if m.Contains(n) && rules.CanStep(m.Elevation(p), m.Elevation(n)) {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+type PathResult struct {
+	// Path contains all points from start to finish including both of them, it's nil if finish isn't reachable
+	Path []Point
+	// Explored is the number of points taken from the queue
+	Explored int
+}
+
+func (r PathResult) Found() bool {
+	return r.Path != nil
+}
+
+// Steps returns number of steps of the path, -1 if there is no path
+func (r PathResult) Steps() int {
+	return len(r.Path) - 1
+}
+
+// PathFinder searches the shortest path between two points of the map, every step costs 1
+type PathFinder interface {
+	FindPath(m ElevationMap, start Point, finish Point, rules ClimbRules) (PathResult, error)
+}
+
+type BFSFinder struct{}
+
+type DijkstraFinder struct{}
+
+// AStarFinder uses manhattan distance to the finish as a heuristic, it's admissible since every step costs 1
+type AStarFinder struct{}
+
+// PathFinders are available algorithms by name
+var PathFinders = map[string]PathFinder{
+	"bfs":      BFSFinder{},
+	"dijkstra": DijkstraFinder{},
+	"astar":    AStarFinder{},
+}
+
+func checkEnds(m ElevationMap, start Point, finish Point) error {
+	if !m.Contains(start) {
+		return fmt.Errorf("start %v is outside of the map", start)
+	}
+	if !m.Contains(finish) {
+		return fmt.Errorf("finish %v is outside of the map", finish)
+	}
+	return nil
+}
+
+// reconstructPath follows prev links from finish back to start
+func reconstructPath(prev map[Point]Point, start Point, finish Point) []Point {
+	res := []Point{finish}
+	for p := finish; p != start; {
+		p = prev[p]
+		res = append(res, p)
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res
+}
+
+func (BFSFinder) FindPath(m ElevationMap, start Point, finish Point, rules ClimbRules) (PathResult, error) {
+	if err := checkEnds(m, start, finish); err != nil {
+		return PathResult{}, err
+	}
+	res := PathResult{}
+	prev := map[Point]Point{start: start}
+	queue := []Point{start}
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		res.Explored++
+		if curr == finish {
+			res.Path = reconstructPath(prev, start, finish)
+			return res, nil
+		}
+		for _, n := range m.Neighbours(curr, rules) {
+			if _, ok := prev[n]; !ok {
+				prev[n] = curr
+				queue = append(queue, n)
+			}
+		}
+	}
+	return res, nil
+}
+
+func (DijkstraFinder) FindPath(m ElevationMap, start Point, finish Point, rules ClimbRules) (PathResult, error) {
+	return bestFirst(m, start, finish, rules, func(Point) int { return 0 })
+}
+
+func (AStarFinder) FindPath(m ElevationMap, start Point, finish Point, rules ClimbRules) (PathResult, error) {
+	return bestFirst(m, start, finish, rules, func(p Point) int { return m1Distance(p, finish) })
+}
+
+type pathNode struct {
+	p Point
+	// distance from start plus heuristic
+	priority int
+	order    int
+}
+
+// pathQueue implements heap.Interface, nodes with the same priority are taken in order of insertion
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int { return len(q) }
+func (q pathQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	return q[i].order < q[j].order
+}
+func (q pathQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *pathQueue) Push(x any) {
+	*q = append(*q, x.(pathNode))
+}
+
+func (q *pathQueue) Pop() any {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// bestFirst is Dijkstra's algorithm, with non zero heuristic it's A*
+func bestFirst(m ElevationMap, start Point, finish Point, rules ClimbRules, heuristic func(Point) int) (PathResult, error) {
+	if err := checkEnds(m, start, finish); err != nil {
+		return PathResult{}, err
+	}
+	res := PathResult{}
+	dist := map[Point]int{start: 0}
+	prev := map[Point]Point{start: start}
+	done := map[Point]bool{}
+	order := 0
+	queue := &pathQueue{{p: start, priority: heuristic(start)}}
+	for queue.Len() > 0 {
+		curr := heap.Pop(queue).(pathNode).p
+		// the point can be in the queue several times, only the first one has the right distance
+		if done[curr] {
+			continue
+		}
+		done[curr] = true
+		res.Explored++
+		if curr == finish {
+			res.Path = reconstructPath(prev, start, finish)
+			return res, nil
+		}
+		for _, n := range m.Neighbours(curr, rules) {
+			d := dist[curr] + 1
+			if old, ok := dist[n]; ok && old <= d {
+				continue
+			}
+			dist[n] = d
+			prev[n] = curr
+			order++
+			heap.Push(queue, pathNode{p: n, priority: d + heuristic(n), order: order})
+		}
+	}
+	return res, nil
+}
+
+// WriteRoute draws the map with the path like in the puzzle description: every point of the path
+// shows direction of the next step, E is the finish and points which aren't on the path are dots
+func WriteRoute(w io.Writer, m ElevationMap, path []Point) error {
+	rows := make([][]byte, len(m.Map))
+	for i := range m.Map {
+		rows[i] = []byte(strings.Repeat(".", len(m.Map[i])))
+	}
+	for i, p := range path {
+		if i == len(path)-1 {
+			rows[p.Y][p.X] = 'E'
+			continue
+		}
+		next := path[i+1]
+		switch {
+		case next.X > p.X:
+			rows[p.Y][p.X] = '>'
+		case next.X < p.X:
+			rows[p.Y][p.X] = '<'
+		case next.Y > p.Y:
+			rows[p.Y][p.X] = 'v'
+		default:
+			rows[p.Y][p.X] = '^'
+		}
+	}
+	for _, r := range rows {
+		if _, err := fmt.Fprintf(w, "%s\n", r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
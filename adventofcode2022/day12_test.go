@@ -0,0 +1,72 @@
+package adventofcode2022_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+var d12Example = []string{
+	"Sabqponm",
+	"abcryxxl",
+	"accszExk",
+	"acctuvwj",
+	"abdefghi",
+}
+
+func TestTask12(t *testing.T) {
+	res, err := adventofcode2022.Task12_1(linesReader(d12Example), adventofcode2022.ToElevationMap, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "Result: 31", res)
+
+	res, err = adventofcode2022.Task12_2(linesReader(d12Example), adventofcode2022.ToElevationMap, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "Result: 29", res)
+}
+
+func TestPathFinders(t *testing.T) {
+	m, err := adventofcode2022.ToElevationMap(linesReader(d12Example))
+	assert.Nil(t, err)
+
+	explored := map[string]int{}
+	for name, finder := range adventofcode2022.PathFinders {
+		res, err := finder.FindPath(m, m.Start, m.Finish, adventofcode2022.PuzzleRules)
+		assert.Nil(t, err)
+		assert.Equal(t, 31, res.Steps(), name)
+		assert.Equal(t, m.Start, res.Path[0], name)
+		assert.Equal(t, m.Finish, res.Path[len(res.Path)-1], name)
+		for i := 1; i < len(res.Path); i++ {
+			prev, curr := res.Path[i-1], res.Path[i]
+			assert.Equal(t, 1, adventofcode2022.Abs(prev.X-curr.X)+adventofcode2022.Abs(prev.Y-curr.Y), name)
+			assert.LessOrEqual(t, m.Elevation(curr)-m.Elevation(prev), 1, name)
+		}
+		explored[name] = res.Explored
+	}
+	assert.LessOrEqual(t, explored["astar"], explored["bfs"])
+
+	res, err := adventofcode2022.AStarFinder{}.FindPath(m, m.Start, m.Finish, adventofcode2022.ClimbRules{MaxAscent: -1, MaxDescent: -1})
+	assert.Nil(t, err)
+	assert.Equal(t, 7, res.Steps())
+
+	// nothing is reachable without climbing
+	res, err = adventofcode2022.BFSFinder{}.FindPath(m, m.Start, m.Finish, adventofcode2022.ClimbRules{MaxAscent: 0, MaxDescent: -1})
+	assert.Nil(t, err)
+	assert.False(t, res.Found())
+	assert.Equal(t, -1, res.Steps())
+
+	_, err = adventofcode2022.DijkstraFinder{}.FindPath(m, adventofcode2022.Point{X: 8, Y: 0}, m.Finish, adventofcode2022.PuzzleRules)
+	assert.NotNil(t, err)
+}
+
+func TestWriteRoute(t *testing.T) {
+	m, _ := adventofcode2022.ToElevationMap(linesReader(d12Example))
+	res, _ := adventofcode2022.BFSFinder{}.FindPath(m, m.Start, m.Finish, adventofcode2022.PuzzleRules)
+	b := strings.Builder{}
+	assert.Nil(t, adventofcode2022.WriteRoute(&b, m, res.Path))
+	route := b.String()
+	assert.Equal(t, 5, strings.Count(route, "\n"))
+	assert.Equal(t, "E", route[2*9+5:2*9+6])
+	assert.Equal(t, 31, len(route)-strings.Count(route, ".")-strings.Count(route, "\n")-1)
+}
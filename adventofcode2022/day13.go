@@ -7,72 +7,128 @@ import (
 	"strings"
 )
 
-type TupleString struct {
-	_1 string
-	_2 string
+// Packet is either an integer or a list of packets
+type Packet struct {
+	IsList bool
+	Value  int
+	List   []Packet
 }
 
-func ToArrTupleString(ir InputReader) ([]TupleString, error) {
-	lines, err := ir.GetInput()
-	if err != nil {
-		return nil, err
-	}
+func IntPacket(v int) Packet {
+	return Packet{Value: v}
+}
 
-	res := []TupleString{}
-	for i := 0; i < len(lines); i += 3 {
-		res = append(res, TupleString{
-			_1: lines[i],
-			_2: lines[i+1],
-		})
+func ListPacket(items ...Packet) Packet {
+	if items == nil {
+		items = []Packet{}
 	}
+	return Packet{IsList: true, List: items}
+}
 
+// ParsePacket parses packet like [1,[2,[]],3], spaces between tokens are allowed,
+// anything else including unbalanced brackets, missing commas and trailing symbols is an error
+func ParsePacket(s string) (Packet, error) {
+	p := &packetParser{s: s}
+	res, err := p.parse()
+	if err != nil {
+		return Packet{}, fmt.Errorf("%v: %w", s, err)
+	}
+	p.skipSpaces()
+	if p.pos < len(s) {
+		return Packet{}, fmt.Errorf("%v: position %v: unexpected symbol after the end of packet: %q", s, p.pos, s[p.pos])
+	}
 	return res, nil
 }
 
-func isWrapped(line string) bool {
-	return strings.HasPrefix(line, "[")
+type packetParser struct {
+	s   string
+	pos int
 }
 
-func unwrapArr(line string) string {
-	return strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+func (p *packetParser) skipSpaces() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
 }
 
-func splitTopLevel(line string) []string {
-	tokens := []string{}
-	opened := 0
-	bld := strings.Builder{}
-	for _, item := range line {
-		if string(item) == "[" {
-			bld.WriteString(string(item))
-			opened++
-			continue
-		} else if string(item) == "]" {
-			bld.WriteString(string(item))
-			opened--
-			if opened == 0 {
-				tokens = append(tokens, bld.String())
-				bld = strings.Builder{}
+func (p *packetParser) parse() (Packet, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.s) {
+		return Packet{}, fmt.Errorf("position %v: unexpected end of packet", p.pos)
+	}
+	c := p.s[p.pos]
+	switch {
+	case c == '[':
+		p.pos++
+		res := ListPacket()
+		p.skipSpaces()
+		if p.pos < len(p.s) && p.s[p.pos] == ']' {
+			p.pos++
+			return res, nil
+		}
+		for {
+			item, err := p.parse()
+			if err != nil {
+				return Packet{}, err
+			}
+			res.List = append(res.List, item)
+			p.skipSpaces()
+			if p.pos >= len(p.s) {
+				return Packet{}, fmt.Errorf("position %v: unexpected end of packet, expected ',' or ']'", p.pos)
+			}
+			switch p.s[p.pos] {
+			case ',':
+				p.pos++
+			case ']':
+				p.pos++
+				return res, nil
+			default:
+				return Packet{}, fmt.Errorf("position %v: expected ',' or ']', got: %q", p.pos, p.s[p.pos])
 			}
-			continue
 		}
+	case c >= '0' && c <= '9':
+		start := p.pos
+		for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			p.pos++
+		}
+		v, err := strconv.Atoi(p.s[start:p.pos])
+		if err != nil {
+			return Packet{}, fmt.Errorf("position %v: %w", start, err)
+		}
+		return IntPacket(v), nil
+	default:
+		return Packet{}, fmt.Errorf("position %v: expected '[' or integer, got: %q", p.pos, c)
+	}
+}
 
-		if opened > 0 {
-			bld.WriteString(string(item))
-		} else if opened == 0 {
-			if string(item) == "," || string(item) == " " {
-				if bld.Len() > 0 {
-					tokens = append(tokens, bld.String())
-					bld = strings.Builder{}
-				}
-			} else {
-				bld.WriteString(string(item))
-			}
+// String returns canonical form of the packet, the same as in the puzzle input
+func (p Packet) String() string {
+	res := strings.Builder{}
+	p.write(&res)
+	return res.String()
+}
+
+func (p Packet) write(b *strings.Builder) {
+	if !p.IsList {
+		b.WriteString(strconv.Itoa(p.Value))
+		return
+	}
+	b.WriteString("[")
+	for i, item := range p.List {
+		if i > 0 {
+			b.WriteString(",")
 		}
+		item.write(b)
 	}
-	if bld.Len() > 0 {
-		tokens = append(tokens, bld.String())
+	b.WriteString("]")
+}
+
+// asList wraps integer into a list with a single item
+func (p Packet) asList() Packet {
+	if p.IsList {
+		return p
 	}
-	return tokens
+	return ListPacket(p)
 }
 
 func compareInt(l int, r int) int {
@@ -85,68 +141,74 @@ func compareInt(l int, r int) int {
 	}
 }
 
-func comparePacket(l string, r string) int {
-	var inl bool = true
-	var inr bool = true
-	nl, err := strconv.Atoi(l)
-	if err != nil {
-		inl = false
+// Compare returns -1 if p is in the right order before o, 1 if it isn't, 0 if packets are equal
+func (p Packet) Compare(o Packet) int {
+	if !p.IsList && !o.IsList {
+		return compareInt(p.Value, o.Value)
 	}
-	nr, err := strconv.Atoi(r)
-	if err != nil {
-		inr = false
+	l, r := p.asList(), o.asList()
+	for i := 0; i < len(l.List) && i < len(r.List); i++ {
+		if cr := l.List[i].Compare(r.List[i]); cr != 0 {
+			return cr
+		}
 	}
+	return compareInt(len(l.List), len(r.List))
+}
 
-	if inl && inr {
-		return compareInt(nl, nr)
-	}
+func (p Packet) Less(o Packet) bool {
+	return p.Compare(o) < 0
+}
 
-	iwl := isWrapped(l)
-	iwr := isWrapped(r)
-	var tokens1 []string
-	var tokens2 []string
+// Packets implements sort.Interface
+type Packets []Packet
 
-	if iwl {
-		tokens1 = splitTopLevel(unwrapArr(l))
-	} else {
-		tokens1 = splitTopLevel(l)
-	}
+func (ps Packets) Len() int           { return len(ps) }
+func (ps Packets) Less(i, j int) bool { return ps[i].Less(ps[j]) }
+func (ps Packets) Swap(i, j int)      { ps[i], ps[j] = ps[j], ps[i] }
 
-	if iwr {
-		tokens2 = splitTopLevel(unwrapArr(r))
-	} else {
-		tokens2 = splitTopLevel(r)
-	}
+type PacketPair struct {
+	Left  Packet
+	Right Packet
+}
 
-	var cr int
+// ToPacketPairs reads pairs of packets separated by empty lines
+func ToPacketPairs(ir InputReader) ([]PacketPair, error) {
+	lines, err := ir.GetInput()
+	if err != nil {
+		return nil, err
+	}
 
-	maxLen := Max(len(tokens1), len(tokens2))
-	for i := 0; i < maxLen; i++ {
-		if len(tokens1) == i {
-			return -1
-		} else if len(tokens2) == i {
-			return 1
+	packets := Packets{}
+	for i, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
 		}
-
-		cr = comparePacket(tokens1[i], tokens2[i])
-		if cr != 0 {
-			return cr
+		p, err := ParsePacket(l)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", i+1, err)
 		}
+		packets = append(packets, p)
+	}
+	if len(packets)%2 != 0 {
+		return nil, fmt.Errorf("expected pairs of packets, got %v packets", len(packets))
+	}
+
+	res := []PacketPair{}
+	for i := 0; i < len(packets); i += 2 {
+		res = append(res, PacketPair{Left: packets[i], Right: packets[i+1]})
 	}
-	return cr
+	return res, nil
 }
 
-func Task13_1(ir InputReader, cnvrtInpt func(InputReader) ([]TupleString, error), debug bool) (string, error) {
-	tuples, err := cnvrtInpt(ir)
+func Task13_1(ir InputReader, cnvrtInpt func(InputReader) ([]PacketPair, error), debug bool) (string, error) {
+	pairs, err := cnvrtInpt(ir)
 	if err != nil {
 		return "", err
 	}
 
-	res := make([]bool, len(tuples))
-	for idx, t := range tuples {
-		cr := comparePacket(t._1, t._2)
-		res[idx] = cr < 0
-
+	res := make([]bool, len(pairs))
+	for idx, p := range pairs {
+		res[idx] = p.Left.Less(p.Right)
 	}
 
 	sum := 0
@@ -157,56 +219,125 @@ func Task13_1(ir InputReader, cnvrtInpt func(InputReader) ([]TupleString, error)
 	}
 
 	if debug {
-		d13p1Debug(res)
+		d13p1Debug(pairs)
 	}
 
 	return fmt.Sprintf("Result: %v", sum), nil
 }
 
-func Task13_2(ir InputReader, cnvrtInpt func(InputReader) ([]TupleString, error), debug bool) (string, error) {
-	tuples, err := cnvrtInpt(ir)
+// DefaultDividers are divider packets from the puzzle: [[2]] and [[6]]
+var DefaultDividers = Packets{
+	ListPacket(ListPacket(IntPacket(2))),
+	ListPacket(ListPacket(IntPacket(6))),
+}
+
+// PairsToPackets returns all packets of pairs in the original order
+func PairsToPackets(pairs []PacketPair) Packets {
+	res := make(Packets, 0, len(pairs)*2)
+	for _, p := range pairs {
+		res = append(res, p.Left, p.Right)
+	}
+	return res
+}
+
+func checkDividers(dividers Packets) error {
+	if len(dividers) == 0 {
+		return fmt.Errorf("expected at least one divider packet")
+	}
+	for i := range dividers {
+		for j := i + 1; j < len(dividers); j++ {
+			if dividers[i].Compare(dividers[j]) == 0 {
+				return fmt.Errorf("duplicate divider packet: %v", dividers[i])
+			}
+		}
+	}
+	return nil
+}
+
+// DividerPositions returns 1-based positions of dividers in the sorted list of packets and dividers
+// without sorting: position of a divider is 1 + number of packets and other dividers less than it.
+// Dividers are placed before packets equal to them
+func DividerPositions(packets Packets, dividers ...Packet) ([]int, error) {
+	if err := checkDividers(dividers); err != nil {
+		return nil, err
+	}
+	res := make([]int, len(dividers))
+	for i, d := range dividers {
+		res[i] = 1
+		for _, o := range dividers {
+			if o.Less(d) {
+				res[i]++
+			}
+		}
+	}
+	for _, p := range packets {
+		for i, d := range dividers {
+			if p.Less(d) {
+				res[i]++
+			}
+		}
+	}
+	return res, nil
+}
+
+// DecoderKey returns product of positions of dividers, see DividerPositions
+func DecoderKey(packets Packets, dividers ...Packet) (int, error) {
+	positions, err := DividerPositions(packets, dividers...)
 	if err != nil {
-		return "", err
+		return 0, err
+	}
+	key := 1
+	for _, p := range positions {
+		key *= p
 	}
+	return key, nil
+}
 
-	tuples = append(tuples, TupleString{
-		_1: "[[2]]",
-		_2: "[[6]]",
-	})
+// SortWithDividers returns sorted copy of packets with inserted dividers, dividers are placed before packets equal to them
+func SortWithDividers(packets Packets, dividers ...Packet) Packets {
+	res := append(append(make(Packets, 0, len(packets)+len(dividers)), dividers...), packets...)
+	sort.Stable(res)
+	return res
+}
 
-	packets := []string{}
-	for _, t := range tuples {
-		packets = append(packets, t._1)
-		packets = append(packets, t._2)
+// DecoderKeyBySort does the same as DecoderKey but finds dividers in the sorted list, it's O(n log n) instead of O(n)
+func DecoderKeyBySort(packets Packets, dividers ...Packet) (int, error) {
+	if err := checkDividers(dividers); err != nil {
+		return 0, err
+	}
+	sorted := SortWithDividers(packets, dividers...)
+	key := 1
+	for _, d := range dividers {
+		key *= sort.Search(len(sorted), func(i int) bool { return !sorted[i].Less(d) }) + 1
 	}
+	return key, nil
+}
 
-	sort.SliceStable(packets, func(i int, j int) bool {
-		cr := comparePacket(packets[i], packets[j])
-		return cr < 0
-	})
+func Task13_2(ir InputReader, cnvrtInpt func(InputReader) ([]PacketPair, error), debug bool) (string, error) {
+	pairs, err := cnvrtInpt(ir)
+	if err != nil {
+		return "", err
+	}
 
-	idx2, idx6 := 0, 0
-	for idx, p := range packets {
-		if p == "[[2]]" {
-			idx2 = idx + 1
-		} else if p == "[[6]]" {
-			idx6 = idx + 1
-		}
+	packets := PairsToPackets(pairs)
+	key, err := DecoderKey(packets, DefaultDividers...)
+	if err != nil {
+		return "", err
 	}
 
 	if debug {
-		d13p2Debug(packets)
+		d13p2Debug(SortWithDividers(packets, DefaultDividers...))
 	}
 
-	return fmt.Sprintf("Result: %v", idx2*idx6), nil
+	return fmt.Sprintf("Result: %v", key), nil
 }
 
-func d13p1Debug(comparison []bool) {
-	for idx, v := range comparison {
-		fmt.Printf("Idx: %v, is right order: %v\n", idx+1, v)
+func d13p1Debug(pairs []PacketPair) {
+	for idx, p := range pairs {
+		fmt.Printf("== Pair %v ==\n%v\n", idx+1, ExplainCompare(p.Left, p.Right))
 	}
 }
-func d13p2Debug(packates []string) {
+func d13p2Debug(packates Packets) {
 	for _, p := range packates {
 		fmt.Printf("%v\n", p)
 	}
@@ -0,0 +1,209 @@
+package adventofcode2022_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+var d13Example = strings.Split(`[1,1,3,1,1]
+[1,1,5,1,1]
+
+[[1],[2,3,4]]
+[[1],4]
+
+[9]
+[[8,7,6]]
+
+[[4,4],4,4]
+[[4,4],4,4,4]
+
+[7,7,7,7]
+[7,7,7]
+
+[]
+[3]
+
+[[[]]]
+[[]]
+
+[1,[2,[3,[4,[5,6,7]]]],8,9]
+[1,[2,[3,[4,[5,6,0]]]],8,9]`, "\n")
+
+func TestTask13(t *testing.T) {
+	res, err := adventofcode2022.Task13_1(linesReader(d13Example), adventofcode2022.ToPacketPairs, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "Result: 13", res)
+
+	res, err = adventofcode2022.Task13_2(linesReader(d13Example), adventofcode2022.ToPacketPairs, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "Result: 140", res)
+}
+
+func TestParsePacket(t *testing.T) {
+	p, err := adventofcode2022.ParsePacket("[1, [2,[ ]],10 ]")
+	assert.Nil(t, err)
+	assert.Equal(t, "[1,[2,[]],10]", p.String())
+	assert.Equal(t, adventofcode2022.ListPacket(
+		adventofcode2022.IntPacket(1),
+		adventofcode2022.ListPacket(adventofcode2022.IntPacket(2), adventofcode2022.ListPacket()),
+		adventofcode2022.IntPacket(10),
+	), p)
+
+	for _, s := range []string{"", "[", "]", "[1,2", "[1,2]]", "[1,,2]", "[1 2]", "[,]", "[1,]", "[a]", "[1]x"} {
+		_, err := adventofcode2022.ParsePacket(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestPacketLess(t *testing.T) {
+	pairs, err := adventofcode2022.ToPacketPairs(linesReader(d13Example))
+	assert.Nil(t, err)
+	packets := []adventofcode2022.Packet{}
+	for _, p := range pairs {
+		packets = append(packets, p.Left, p.Right)
+	}
+	sort.Slice(packets, func(i, j int) bool { return packets[i].Less(packets[j]) })
+	assert.Equal(t, "[]", packets[0].String())
+	assert.Equal(t, "[[]]", packets[1].String())
+	assert.Equal(t, "[9]", packets[len(packets)-1].String())
+	assert.Equal(t, 0, packets[0].Compare(adventofcode2022.ListPacket()))
+
+	_, err = adventofcode2022.ToPacketPairs(linesReader([]string{"[1]", "", "[2]", "[3]"}))
+	assert.NotNil(t, err)
+}
+
+func TestCompareTrace(t *testing.T) {
+	l, _ := adventofcode2022.ParsePacket("[[1],[2,3,4]]")
+	r, _ := adventofcode2022.ParsePacket("[[1],4]")
+	cr, steps := l.CompareTrace(r)
+	assert.Equal(t, -1, cr)
+	assert.Equal(t, adventofcode2022.StepPromoteRight, steps[4].Kind)
+	assert.Equal(t, []int{1}, steps[4].Path)
+	assert.Equal(t, adventofcode2022.StepLeftSmaller, steps[len(steps)-1].Kind)
+	assert.Equal(t, []int{1, 0}, steps[len(steps)-1].Path)
+
+	expected := `- Compare [[1],[2,3,4]] vs [[1],4]
+  - Compare [1] vs [1]
+    - Compare 1 vs 1
+  - Compare [2,3,4] vs 4
+    - Mixed types; convert right to [4] and retry comparison
+    - Compare [2,3,4] vs [4]
+      - Compare 2 vs 4
+        - Left side is smaller, so inputs are in the right order
+`
+	assert.Equal(t, expected, adventofcode2022.ExplainCompare(l, r))
+
+	pairs, err := adventofcode2022.ToPacketPairs(linesReader(d13Example))
+	assert.Nil(t, err)
+	for _, p := range pairs {
+		cr, _ := p.Left.CompareTrace(p.Right)
+		assert.Equal(t, p.Left.Compare(p.Right), cr, p.Left.String())
+	}
+}
+
+func TestDecoderKey(t *testing.T) {
+	pairs, err := adventofcode2022.ToPacketPairs(linesReader(d13Example))
+	assert.Nil(t, err)
+	packets := adventofcode2022.PairsToPackets(pairs)
+
+	positions, err := adventofcode2022.DividerPositions(packets, adventofcode2022.DefaultDividers...)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{10, 14}, positions)
+
+	dividers := adventofcode2022.Packets{adventofcode2022.IntPacket(5), adventofcode2022.ListPacket()}
+	key, err := adventofcode2022.DecoderKey(packets, dividers...)
+	assert.Nil(t, err)
+	sortKey, err := adventofcode2022.DecoderKeyBySort(packets, dividers...)
+	assert.Nil(t, err)
+	assert.Equal(t, sortKey, key)
+	// [] is the first packet, only [7,7,7], [7,7,7,7], [9] and [[8,7,6]] aren't less than 5
+	assert.Equal(t, 1*14, key)
+
+	_, err = adventofcode2022.DecoderKey(packets)
+	assert.NotNil(t, err)
+	_, err = adventofcode2022.DecoderKey(packets, adventofcode2022.IntPacket(1), adventofcode2022.ListPacket(adventofcode2022.IntPacket(1)))
+	assert.NotNil(t, err)
+}
+
+func randomPacket(rnd *rand.Rand, depth int) adventofcode2022.Packet {
+	if depth == 0 || rnd.Intn(3) == 0 {
+		return adventofcode2022.IntPacket(rnd.Intn(11))
+	}
+	items := make([]adventofcode2022.Packet, rnd.Intn(5))
+	for i := range items {
+		items[i] = randomPacket(rnd, depth-1)
+	}
+	return adventofcode2022.ListPacket(items...)
+}
+
+func randomPackets(n int) adventofcode2022.Packets {
+	rnd := rand.New(rand.NewSource(13))
+	res := make(adventofcode2022.Packets, n)
+	for i := range res {
+		res[i] = adventofcode2022.ListPacket(randomPacket(rnd, 4), randomPacket(rnd, 4))
+	}
+	return res
+}
+
+// decoderKeyByStrings is the approach DecoderKey replaced: raw packets and dividers are sorted together
+// and every comparison parses both packets again
+func decoderKeyByStrings(raw []string, dividers ...string) int {
+	sorted := append(append(make([]string, 0, len(raw)+len(dividers)), dividers...), raw...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		l, _ := adventofcode2022.ParsePacket(sorted[i])
+		r, _ := adventofcode2022.ParsePacket(sorted[j])
+		return l.Less(r)
+	})
+	key := 1
+	for _, d := range dividers {
+		for idx, p := range sorted {
+			if p == d {
+				key *= idx + 1
+				break
+			}
+		}
+	}
+	return key
+}
+
+func TestDecoderKeyByStrings(t *testing.T) {
+	packets := randomPackets(100)
+	raw := make([]string, len(packets))
+	for i, p := range packets {
+		raw[i] = p.String()
+	}
+	key, err := adventofcode2022.DecoderKey(packets, adventofcode2022.DefaultDividers...)
+	assert.Nil(t, err)
+	assert.Equal(t, key, decoderKeyByStrings(raw, "[[2]]", "[[6]]"))
+}
+
+func BenchmarkDecoderKey(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		packets := randomPackets(n)
+		raw := make([]string, n)
+		for i, p := range packets {
+			raw[i] = p.String()
+		}
+		b.Run(fmt.Sprintf("strings/%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				decoderKeyByStrings(raw, "[[2]]", "[[6]]")
+			}
+		})
+		b.Run(fmt.Sprintf("count/%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				adventofcode2022.DecoderKey(packets, adventofcode2022.DefaultDividers...)
+			}
+		})
+		b.Run(fmt.Sprintf("sort/%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				adventofcode2022.DecoderKeyBySort(packets, adventofcode2022.DefaultDividers...)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package adventofcode2022
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type CompareStepKind int
+
+const (
+	// StepCompare is a comparison of two values, it's followed by steps of the comparison
+	StepCompare CompareStepKind = iota
+	// StepPromoteLeft and StepPromoteRight are conversions of an integer to a list with a single item
+	StepPromoteLeft
+	StepPromoteRight
+	StepLeftSmaller
+	StepRightSmaller
+	StepLeftRanOut
+	StepRightRanOut
+)
+
+// CompareStep is a single decision made while comparing packets
+type CompareStep struct {
+	Kind CompareStepKind
+	// nesting level of the step, used for indentation
+	Depth int
+	// indexes of compared values from the top level lists, empty for packets themselves
+	Path  []int
+	Left  Packet
+	Right Packet
+}
+
+func (s CompareStep) String() string {
+	switch s.Kind {
+	case StepCompare:
+		return fmt.Sprintf("Compare %v vs %v", s.Left, s.Right)
+	case StepPromoteLeft:
+		return fmt.Sprintf("Mixed types; convert left to %v and retry comparison", s.Left.asList())
+	case StepPromoteRight:
+		return fmt.Sprintf("Mixed types; convert right to %v and retry comparison", s.Right.asList())
+	case StepLeftSmaller:
+		return "Left side is smaller, so inputs are in the right order"
+	case StepRightSmaller:
+		return "Right side is smaller, so inputs are not in the right order"
+	case StepLeftRanOut:
+		return "Left side ran out of items, so inputs are in the right order"
+	case StepRightRanOut:
+		return "Right side ran out of items, so inputs are not in the right order"
+	}
+	return fmt.Sprintf("unknown step: %v", int(s.Kind))
+}
+
+// CompareTrace compares packets the same way as Compare does and records every decision
+func (p Packet) CompareTrace(o Packet) (int, []CompareStep) {
+	steps := []CompareStep{}
+	res := traceCompare(p, o, 0, []int{}, &steps)
+	return res, steps
+}
+
+func traceCompare(l Packet, r Packet, depth int, path []int, steps *[]CompareStep) int {
+	step := func(kind CompareStepKind, depth int) {
+		*steps = append(*steps, CompareStep{Kind: kind, Depth: depth, Path: path, Left: l, Right: r})
+	}
+	step(StepCompare, depth)
+
+	switch {
+	case !l.IsList && !r.IsList:
+		cr := compareInt(l.Value, r.Value)
+		if cr < 0 {
+			step(StepLeftSmaller, depth+1)
+		} else if cr > 0 {
+			step(StepRightSmaller, depth+1)
+		}
+		return cr
+	case !l.IsList:
+		step(StepPromoteLeft, depth+1)
+		return traceCompare(l.asList(), r, depth+1, path, steps)
+	case !r.IsList:
+		step(StepPromoteRight, depth+1)
+		return traceCompare(l, r.asList(), depth+1, path, steps)
+	}
+
+	for i := 0; i < len(l.List) && i < len(r.List); i++ {
+		itemPath := append(append(make([]int, 0, len(path)+1), path...), i)
+		if cr := traceCompare(l.List[i], r.List[i], depth+1, itemPath, steps); cr != 0 {
+			return cr
+		}
+	}
+	cr := compareInt(len(l.List), len(r.List))
+	if cr < 0 {
+		step(StepLeftRanOut, depth+1)
+	} else if cr > 0 {
+		step(StepRightRanOut, depth+1)
+	}
+	return cr
+}
+
+// WriteTrace writes steps as an indented list like in the puzzle description
+func WriteTrace(w io.Writer, steps []CompareStep) error {
+	for _, s := range steps {
+		if _, err := fmt.Fprintf(w, "%v- %v\n", strings.Repeat("  ", s.Depth), s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExplainCompare returns human readable explanation of comparison of two packets
+func ExplainCompare(l Packet, r Packet) string {
+	_, steps := l.CompareTrace(r)
+	res := strings.Builder{}
+	WriteTrace(&res, steps)
+	return res.String()
+}
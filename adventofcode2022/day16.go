@@ -7,10 +7,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-
-	combinations "github.com/mxschmitt/golang-combinations"
 )
 
+// valvesCount is the number of possible valve names from AA to ZZ
+const valvesCount = 26 * 26
+
 func idx(s string) int {
 	fst := []rune(s)[0]
 	scnd := []rune(s)[1]
@@ -32,9 +33,9 @@ func ToAdjacencyMatrix(ir InputReader) (Day16Inpt, error) {
 	toValvesRe := regexp.MustCompile("valves? (([A-Z][A-Z](, )?)+)")
 	rateRe := regexp.MustCompile("rate=(\\d+)")
 	valves := map[int]int{}
-	m := make([][]int, 675)
+	m := make([][]int, valvesCount)
 	for i := 0; i < len(m); i++ {
-		m[i] = make([]int, 675)
+		m[i] = make([]int, valvesCount)
 		for j := 0; j < len(m[i]); j++ {
 			if i == j {
 				m[i][j] = 0
@@ -61,9 +62,15 @@ func ToAdjacencyMatrix(ir InputReader) (Day16Inpt, error) {
 		if err != nil {
 			return Day16Inpt{}, err
 		}
-		idxFrom := idx(from[1])
+		idxFrom, err := valveIdx(from[1], len(m))
+		if err != nil {
+			return Day16Inpt{}, err
+		}
 		for _, t := range toList {
-			idxTo := idx(strings.TrimSpace(t))
+			idxTo, err := valveIdx(strings.TrimSpace(t), len(m))
+			if err != nil {
+				return Day16Inpt{}, err
+			}
 			m[idxFrom][idxTo] = 1
 			m[idxTo][idxFrom] = 1
 		}
@@ -79,74 +86,270 @@ func ToAdjacencyMatrix(ir InputReader) (Day16Inpt, error) {
 	return res, nil
 }
 
-// floyd-warshall to generate shortest distances between vertexes
-func fw(dist [][]int) {
-	for k := 0; k < 675; k++ {
-		for i := 0; i < 675; i++ {
-			for j := 0; j < 675; j++ {
-				if dist[i][k]+dist[k][j] < dist[i][j] {
-					dist[i][j] = dist[i][k] + dist[k][j]
-				}
-			}
-		}
+// ValveSolver searches for the maximum pressure released by several agents
+// opening valves in parallel, every agent starts from the same valve
+type ValveSolver struct {
+	Agents  int
+	Minutes int
+	Start   string
+}
+
+// MaxPressure returns maximum pressure which agents can release together
+func (vs ValveSolver) MaxPressure(input Day16Inpt) (int, error) {
+	plan, err := vs.Plan(input)
+	if err != nil {
+		return 0, err
+	}
+	return plan.Pressure, nil
+}
+
+// Plan computes for a single agent the best pressure for every set of opened valves (bitmask DP),
+// then distributes valves between agents as a subset-sum over disjoint masks
+// and restores the order in which every agent opens its valves
+func (vs ValveSolver) Plan(input Day16Inpt) (ValvePlan, error) {
+	if vs.Agents < 1 {
+		return ValvePlan{}, fmt.Errorf("expected at least 1 agent, got: %v", vs.Agents)
+	}
+	if vs.Minutes < 0 {
+		return ValvePlan{}, fmt.Errorf("expected non negative time budget, got: %v", vs.Minutes)
+	}
+	start, err := valveIdx(vs.Start, len(input.AdjacenyM))
+	if err != nil {
+		return ValvePlan{}, err
+	}
+
+	vg, err := newValveGraph(input, start, vs.maxValves())
+	if err != nil {
+		return ValvePlan{}, err
+	}
+
+	best, routes := vg.bestPerMask(vs.Minutes)
+
+	// splits[a][mask] keeps valves opened by agent a+1 when valves from mask opened by agents 0..a+1,
+	// the last agent needs only the split of all valves
+	splits := make([][]int, Max(vs.Agents-2, 0))
+	total := best
+	for a := 1; a < vs.Agents-1; a++ {
+		total, splits[a-1] = combineMasks(best, total)
 	}
+
+	mask := len(total) - 1
+	pressure := total[mask]
+	agentRoutes := make([][]int, vs.Agents)
+	if vs.Agents > 1 {
+		var sub int
+		pressure, sub = combineAll(best, total)
+		agentRoutes[vs.Agents-1] = routes[sub]
+		mask ^= sub
+	}
+	for a := vs.Agents - 2; a > 0; a-- {
+		sub := splits[a-1][mask]
+		agentRoutes[a] = routes[sub]
+		mask ^= sub
+	}
+	agentRoutes[0] = routes[mask]
+
+	plan := ValvePlan{
+		Start:    vs.Start,
+		Minutes:  vs.Minutes,
+		Pressure: pressure,
+		Agents:   make([]AgentPlan, vs.Agents),
+	}
+	for a, route := range agentRoutes {
+		plan.Agents[a] = vg.agentPlan(a+1, route, vs.Minutes)
+	}
+	return plan, nil
 }
 
-type SearchState struct {
-	MinLeft  int
-	Pressure int
+// valveIdx returns index of the valve in adjacency matrix of the given size
+func valveIdx(name string, size int) (int, error) {
+	if len(name) != 2 || name[0] < 'A' || name[0] > 'Z' || name[1] < 'A' || name[1] > 'Z' {
+		return 0, fmt.Errorf("expected valve name in format: [AA], got: %v", name)
+	}
+	i := idx(name)
+	if i >= size {
+		return 0, fmt.Errorf("valve %v is out of range of %v valves", name, size)
+	}
+	return i, nil
 }
 
-func conatins(v int, arr []int) bool {
-	for _, i := range arr {
-		if v == i {
-			return true
-		}
+func valveName(i int) string {
+	return string([]byte{byte('A' + i/26), byte('A' + i%26)})
+}
+
+// Limits of number of valves with positive flow rate. DP keeps pressure and route for each of 2^n sets of valves,
+// the last agent is combined with the others in O(2^n), but every agent between the first and the last one
+// needs the best split of every set which is O(3^n)
+const (
+	maxValves           = 20
+	maxValvesManyAgents = 15
+)
+
+func (vs ValveSolver) maxValves() int {
+	if vs.Agents > 2 {
+		return maxValvesManyAgents
 	}
-	return false
+	return maxValves
 }
 
-func search(graph [][]int, curVertex int, path []int, prevKey string, storage map[string]SearchState, valves map[int]int, initMinutes int) {
-	for valve, pres := range valves {
-		if valve == curVertex || conatins(valve, path) {
-			continue
+// valveGraph is a compressed graph which contains only valves with positive flow rate,
+// start valve is always the last one
+type valveGraph struct {
+	valves []int
+	rates  []int
+	dist   [][]int
+}
+
+func newValveGraph(input Day16Inpt, start int, limit int) (valveGraph, error) {
+	if start < 0 || start >= len(input.AdjacenyM) {
+		return valveGraph{}, fmt.Errorf("start valve %v is out of range of %v valves", valveName(start), len(input.AdjacenyM))
+	}
+	valves := []int{}
+	for v, r := range input.ValvesPressure {
+		if v < 0 || v >= len(input.AdjacenyM) {
+			return valveGraph{}, fmt.Errorf("valve %v is out of range of %v valves", valveName(v), len(input.AdjacenyM))
+		}
+		if r > 0 {
+			valves = append(valves, v)
+		}
+	}
+	if len(valves) > limit {
+		return valveGraph{}, fmt.Errorf("expected at most %v valves with positive flow rate, got: %v", limit, len(valves))
+	}
+	sort.Ints(valves)
+
+	rates := make([]int, len(valves))
+	for i, v := range valves {
+		rates[i] = input.ValvesPressure[v]
+	}
+
+	nodes := append(append([]int{}, valves...), start)
+	dist := make([][]int, len(nodes))
+	for i, from := range nodes {
+		d := bfsValves(input.AdjacenyM, from)
+		dist[i] = make([]int, len(nodes))
+		for j, to := range nodes {
+			dist[i][j] = d[to]
 		}
-		prev, ok := storage[prevKey]
-		if !ok {
-			prev = SearchState{
-				MinLeft:  initMinutes,
-				Pressure: 0,
+	}
+
+	return valveGraph{
+		valves: valves,
+		rates:  rates,
+		dist:   dist,
+	}, nil
+}
+
+// shortest distances from valve to all others, unreachable valves have math.MaxInt32 distance
+func bfsValves(m [][]int, from int) []int {
+	dist := make([]int, len(m))
+	for i := range dist {
+		dist[i] = math.MaxInt32
+	}
+	dist[from] = 0
+	queue := []int{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for next, w := range m[cur] {
+			if w == 1 && dist[next] == math.MaxInt32 {
+				dist[next] = dist[cur] + 1
+				queue = append(queue, next)
 			}
 		}
-		
-		nPath := make([]int, len(path)+1)
-		copy(nPath, path)
-		nPath[len(path)] = valve
-		nMinLeft := prev.MinLeft - graph[valve][curVertex] - 1
-		nPressure := prev.Pressure + nMinLeft*pres
+	}
+	return dist
+}
 
-		nKey := buildKey(nPath)
+// bestPerMask returns best pressure single agent can release opening only valves from mask
+// and the order in which valves are opened to get it
+func (vg valveGraph) bestPerMask(minutes int) ([]int, [][]int) {
+	best := make([]int, 1<<len(vg.valves))
+	routes := make([][]int, len(best))
+	vg.walk(len(vg.valves), []int{}, 0, minutes, 0, best, routes)
 
-		nV, nOk := storage[nKey]
-		if nOk && nV.Pressure > nPressure {
+	// best for mask is at least best of any its subset
+	for bit := 1; bit < len(best); bit <<= 1 {
+		for mask := range best {
+			if mask&bit != 0 && best[mask^bit] > best[mask] {
+				best[mask] = best[mask^bit]
+				routes[mask] = routes[mask^bit]
+			}
+		}
+	}
+	return best, routes
+}
+
+func (vg valveGraph) walk(cur int, route []int, mask int, minLeft int, pressure int, best []int, routes [][]int) {
+	if pressure > best[mask] {
+		best[mask] = pressure
+		routes[mask] = append([]int{}, route...)
+	}
+	for v := range vg.valves {
+		if mask&(1<<v) != 0 {
 			continue
 		}
-		storage[nKey] = SearchState{
-			MinLeft:  nMinLeft,
-			Pressure: nPressure,
+		nMinLeft := minLeft - vg.dist[cur][v] - 1
+		if nMinLeft <= 0 {
+			continue
 		}
+		vg.walk(v, append(route, v), mask|1<<v, nMinLeft, pressure+nMinLeft*vg.rates[v], best, routes)
+	}
+}
+
+// combineMasks adds one more agent: for every mask picks the best split
+// into valves opened by the new agent and valves opened by the previous ones
+func combineMasks(agent []int, prev []int) ([]int, []int) {
+	res := make([]int, len(prev))
+	split := make([]int, len(prev))
+	for mask := range res {
+		for sub := mask; ; sub = (sub - 1) & mask {
+			if agent[sub]+prev[mask^sub] > res[mask] {
+				res[mask] = agent[sub] + prev[mask^sub]
+				split[mask] = sub
+			}
+			if sub == 0 {
+				break
+			}
+		}
+	}
+	return res, split
+}
 
-		search(graph, valve, nPath, nKey, storage, valves, initMinutes)
+// combineAll is combineMasks for the set of all valves only, it's enough for the last agent
+func combineAll(agent []int, prev []int) (int, int) {
+	full := len(prev) - 1
+	res, split := 0, 0
+	for sub := range agent {
+		if agent[sub]+prev[full^sub] > res {
+			res = agent[sub] + prev[full^sub]
+			split = sub
+		}
 	}
+	return res, split
 }
 
-func buildKey(path []int) string {
-	sort.Ints(path)
-	str := strings.Builder{}
-	for _, i := range path {
-		str.WriteString(fmt.Sprintf("%v:", i))
+// agentPlan replays route of compressed valves and calculates when every valve is opened
+func (vg valveGraph) agentPlan(agent int, route []int, minutes int) AgentPlan {
+	plan := AgentPlan{
+		Agent:    agent,
+		Openings: []ValveOpening{},
+	}
+	cur := len(vg.valves)
+	minLeft := minutes
+	for _, v := range route {
+		minLeft -= vg.dist[cur][v] + 1
+		pressure := minLeft * vg.rates[v]
+		plan.Openings = append(plan.Openings, ValveOpening{
+			Valve:    valveName(vg.valves[v]),
+			Rate:     vg.rates[v],
+			Minute:   minutes - minLeft,
+			Pressure: pressure,
+		})
+		plan.Pressure += pressure
+		cur = v
 	}
-	return str.String()
+	return plan
 }
 
 func Task16_1(ir InputReader, cnvrtInpt func(InputReader) (Day16Inpt, error), debug bool) (string, error) {
@@ -155,21 +358,16 @@ func Task16_1(ir InputReader, cnvrtInpt func(InputReader) (Day16Inpt, error), de
 		return "", err
 	}
 
-	fw(input.AdjacenyM)
-
-	storage := map[string]SearchState{}
-	path := []int{}
-
-	search(input.AdjacenyM, 0, path, "", storage, input.ValvesPressure, 30)
+	plan, err := ValveSolver{Agents: 1, Minutes: 30, Start: "AA"}.Plan(input)
+	if err != nil {
+		return "", err
+	}
 
-	maxP := 0
-	for _, v := range storage {
-		if v.Pressure > maxP {
-			maxP = v.Pressure
-		}
+	if debug {
+		debugD16Plan(plan)
 	}
 
-	return fmt.Sprintf("%v", maxP), nil
+	return fmt.Sprintf("%v", plan.Pressure), nil
 }
 
 func Task16_2(ir InputReader, cnvrtInpt func(InputReader) (Day16Inpt, error), debug bool) (string, error) {
@@ -178,50 +376,14 @@ func Task16_2(ir InputReader, cnvrtInpt func(InputReader) (Day16Inpt, error), de
 		return "", err
 	}
 
-	fw(input.AdjacenyM)
-
-	storage := map[string]SearchState{}
-	path := []int{}
-
-	search(input.AdjacenyM, 0, path, "", storage, input.ValvesPressure, 26)
-
-	maxP := 0
-	for _, v := range storage {
-		if v.Pressure > maxP {
-			maxP = v.Pressure
-		}
+	plan, err := ValveSolver{Agents: 2, Minutes: 26, Start: "AA"}.Plan(input)
+	if err != nil {
+		return "", err
 	}
 
-	filteredV := []int{}
-	for k, v := range input.ValvesPressure {
-		if v != 0 {
-			filteredV = append(filteredV, k)
-		}
+	if debug {
+		debugD16Plan(plan)
 	}
 
-	subsets := combinations.All(filteredV)
-	MaxOf2 := 0
-	for _, i := range subsets {
-		ik := buildKey(i)
-		for _, j := range subsets {
-			if isDisjoint(i, j) {
-				jk := buildKey(j)
-				if storage[ik].Pressure+storage[jk].Pressure > MaxOf2 {
-					MaxOf2 = storage[ik].Pressure + storage[jk].Pressure
-				}
-			}
-		}
-	}
-	return fmt.Sprintf("%v", MaxOf2), nil
-}
-
-func isDisjoint(arr1 []int, arr2 []int) bool {
-	for i := 0; i < len(arr1); i++ {
-		for j := 0; j < len(arr2); j++ {
-			if arr1[i] == arr2[j] {
-				return false
-			}
-		}
-	}
-	return true
+	return fmt.Sprintf("%v", plan.Pressure), nil
 }
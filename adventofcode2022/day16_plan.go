@@ -0,0 +1,87 @@
+package adventofcode2022
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+type ValveOpening struct {
+	Valve    string `json:"valve"`
+	Rate     int    `json:"rate"`
+	Minute   int    `json:"minute"`
+	Pressure int    `json:"pressure"`
+}
+
+type AgentPlan struct {
+	Agent    int            `json:"agent"`
+	Openings []ValveOpening `json:"openings"`
+	Pressure int            `json:"pressure"`
+}
+
+type ValvePlan struct {
+	Start    string      `json:"start"`
+	Minutes  int         `json:"minutes"`
+	Pressure int         `json:"pressure"`
+	Agents   []AgentPlan `json:"agents"`
+}
+
+// WriteTimeline prints all openings of all agents ordered by minute
+func (p ValvePlan) WriteTimeline(writer io.Writer) error {
+	type row struct {
+		agent int
+		ValveOpening
+	}
+	rows := []row{}
+	for _, a := range p.Agents {
+		for _, o := range a.Openings {
+			rows = append(rows, row{a.Agent, o})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Minute == rows[j].Minute {
+			return rows[i].agent < rows[j].agent
+		}
+		return rows[i].Minute < rows[j].Minute
+	})
+
+	tw := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "minute\tagent\tvalve\trate\tpressure\ttotal\n")
+	total := 0
+	for _, r := range rows {
+		total += r.Pressure
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\n", r.Minute, r.agent, r.Valve, r.Rate, r.Pressure, total)
+	}
+	return tw.Flush()
+}
+
+func (p ValvePlan) WriteJSON(writer io.Writer) error {
+	enc := json.NewEncoder(writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+func debugD16Plan(plan ValvePlan) {
+	f, err := os.Create("debug_16d_plan.debug")
+	if err != nil {
+		fmt.Printf("can't print debug: %v\n", err)
+	} else {
+		defer f.Close()
+		if err := plan.WriteTimeline(f); err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+		}
+	}
+
+	f, err = os.Create("debug_16d_plan.json")
+	if err != nil {
+		fmt.Printf("can't print debug: %v\n", err)
+	} else {
+		defer f.Close()
+		if err := plan.WriteJSON(f); err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+		}
+	}
+}
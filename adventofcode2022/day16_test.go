@@ -1,6 +1,8 @@
 package adventofcode2022_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
 	"github.com/asstart/advent-of-code-2022/adventofcode2022"
@@ -45,4 +47,150 @@ func TestTask16_2(t *testing.T) {
 	)
 	assert.Nil(t, err)
 	assert.Equal(t, "1933", res)
-}
\ No newline at end of file
+}
+func TestValveSolver(t *testing.T) {
+	input, err := adventofcode2022.ToAdjacencyMatrix(
+		&adventofcode2022.FileToStringsInputReader{Path: "../adventofcode2022/day16.data"},
+	)
+	assert.Nil(t, err)
+
+	tt := []struct {
+		name     string
+		solver   adventofcode2022.ValveSolver
+		expected int
+	}{
+		{"single agent", adventofcode2022.ValveSolver{Agents: 1, Minutes: 30, Start: "AA"}, 1376},
+		{"two agents", adventofcode2022.ValveSolver{Agents: 2, Minutes: 26, Start: "AA"}, 1933},
+		{"no time", adventofcode2022.ValveSolver{Agents: 3, Minutes: 0, Start: "AA"}, 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := tc.solver.MaxPressure(input)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestValveSolverMoreAgents(t *testing.T) {
+	input, err := adventofcode2022.ToAdjacencyMatrix(
+		&adventofcode2022.FileToStringsInputReader{Path: "../adventofcode2022/day16.data"},
+	)
+	assert.Nil(t, err)
+
+	two, err := adventofcode2022.ValveSolver{Agents: 2, Minutes: 20, Start: "AA"}.MaxPressure(input)
+	assert.Nil(t, err)
+	three, err := adventofcode2022.ValveSolver{Agents: 3, Minutes: 20, Start: "AA"}.MaxPressure(input)
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, three, two)
+
+	_, err = adventofcode2022.ValveSolver{Agents: 0, Minutes: 20, Start: "AA"}.MaxPressure(input)
+	assert.NotNil(t, err)
+	_, err = adventofcode2022.ValveSolver{Agents: 1, Minutes: 20, Start: "A1"}.MaxPressure(input)
+	assert.NotNil(t, err)
+	_, err = adventofcode2022.ValveSolver{Agents: 1, Minutes: 20, Start: "Z"}.MaxPressure(input)
+	assert.NotNil(t, err)
+}
+
+func TestValveSolverLastValve(t *testing.T) {
+	input, err := adventofcode2022.ToAdjacencyMatrix(linesReader([]string{
+		"Valve AA has flow rate=0; tunnels lead to valves BB, ZZ",
+		"Valve BB has flow rate=2; tunnels lead to valves AA",
+		"Valve ZZ has flow rate=10; tunnels lead to valves AA",
+	}))
+	assert.Nil(t, err)
+
+	// ZZ is opened at minute 2 and releases pressure for 3 minutes, BB is opened at minute 5
+	res, err := adventofcode2022.ValveSolver{Agents: 1, Minutes: 5, Start: "AA"}.MaxPressure(input)
+	assert.Nil(t, err)
+	assert.Equal(t, 30, res)
+
+	// starting from ZZ it's opened at minute 1, BB at minute 4
+	res, err = adventofcode2022.ValveSolver{Agents: 1, Minutes: 5, Start: "ZZ"}.MaxPressure(input)
+	assert.Nil(t, err)
+	assert.Equal(t, 42, res)
+}
+
+func TestValveSolverLimits(t *testing.T) {
+	input := adventofcode2022.Day16Inpt{AdjacenyM: make([][]int, 16), ValvesPressure: map[int]int{}}
+	for i := range input.AdjacenyM {
+		input.AdjacenyM[i] = make([]int, 16)
+		input.ValvesPressure[i] = 1
+	}
+
+	_, err := adventofcode2022.ValveSolver{Agents: 2, Minutes: 0, Start: "AA"}.MaxPressure(input)
+	assert.Nil(t, err)
+	_, err = adventofcode2022.ValveSolver{Agents: 3, Minutes: 0, Start: "AA"}.MaxPressure(input)
+	assert.NotNil(t, err)
+}
+
+func TestValveSolverPlan(t *testing.T) {
+	input, err := adventofcode2022.ToAdjacencyMatrix(
+		&adventofcode2022.FileToStringsInputReader{Path: "../adventofcode2022/day16.data"},
+	)
+	assert.Nil(t, err)
+
+	plan, err := adventofcode2022.ValveSolver{Agents: 2, Minutes: 26, Start: "AA"}.Plan(input)
+	assert.Nil(t, err)
+	assert.Equal(t, 1933, plan.Pressure)
+	assert.Len(t, plan.Agents, 2)
+
+	opened := map[string]bool{}
+	total := 0
+	for _, a := range plan.Agents {
+		prevMinute := 0
+		for _, o := range a.Openings {
+			assert.False(t, opened[o.Valve])
+			opened[o.Valve] = true
+			assert.Greater(t, o.Minute, prevMinute)
+			assert.Equal(t, (26-o.Minute)*o.Rate, o.Pressure)
+			prevMinute = o.Minute
+			total += o.Pressure
+		}
+	}
+	assert.Equal(t, plan.Pressure, total)
+}
+
+var d16Example = linesReader{
+	"Valve AA has flow rate=0; tunnels lead to valves DD, II, BB",
+	"Valve BB has flow rate=13; tunnels lead to valves CC, AA",
+	"Valve CC has flow rate=2; tunnels lead to valves DD, BB",
+	"Valve DD has flow rate=20; tunnels lead to valves CC, AA, EE",
+	"Valve EE has flow rate=3; tunnels lead to valves FF, DD",
+	"Valve FF has flow rate=0; tunnels lead to valves EE, GG",
+	"Valve GG has flow rate=0; tunnels lead to valves FF, HH",
+	"Valve HH has flow rate=22; tunnel leads to valve GG",
+	"Valve II has flow rate=0; tunnels lead to valves AA, JJ",
+	"Valve JJ has flow rate=21; tunnel leads to valve II",
+}
+
+func TestValvePlanExport(t *testing.T) {
+	input, err := adventofcode2022.ToAdjacencyMatrix(d16Example)
+	assert.Nil(t, err)
+	plan, err := adventofcode2022.ValveSolver{Agents: 1, Minutes: 30, Start: "AA"}.Plan(input)
+	assert.Nil(t, err)
+	assert.Equal(t, 1651, plan.Pressure)
+
+	buf := bytes.Buffer{}
+	assert.Nil(t, plan.WriteTimeline(&buf))
+	assert.Equal(t, `minute  agent  valve  rate  pressure  total
+2       1      DD     20    560       560
+5       1      BB     13    325       885
+9       1      JJ     21    441       1326
+17      1      HH     22    286       1612
+21      1      EE     3     27        1639
+24      1      CC     2     12        1651
+`, buf.String())
+
+	for agents, expected := range map[int]int{1: 1327, 2: 1707} {
+		plan, err := adventofcode2022.ValveSolver{Agents: agents, Minutes: 26, Start: "AA"}.Plan(input)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, plan.Pressure)
+		buf.Reset()
+		assert.Nil(t, plan.WriteJSON(&buf))
+		decoded := adventofcode2022.ValvePlan{}
+		assert.Nil(t, json.Unmarshal(buf.Bytes(), &decoded))
+		assert.Equal(t, plan, decoded)
+	}
+}
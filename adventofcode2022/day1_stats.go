@@ -0,0 +1,209 @@
+package adventofcode2022
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// intMinHeap implements heap.Interface, the smallest value is on top
+type intMinHeap []int
+
+func (h intMinHeap) Len() int           { return len(h) }
+func (h intMinHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h intMinHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *intMinHeap) Push(x any) {
+	*h = append(*h, x.(int))
+}
+
+func (h *intMinHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// CalorieStats accumulates totals of elves one by one, K biggest totals are kept in a min heap,
+// so adding a total is O(log K) and memory is O(K). Percentiles, median and histogram need all totals,
+// they are available only if KeepTotals is set
+type CalorieStats struct {
+	K          int
+	KeepTotals bool
+	Count      int
+	Sum        int
+	Min        int
+	Max        int
+
+	top    intMinHeap
+	totals []int
+	sorted bool
+}
+
+func NewCalorieStats(k int, keepTotals bool) (*CalorieStats, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("expected k >= 1, got: %v", k)
+	}
+	return &CalorieStats{K: k, KeepTotals: keepTotals, top: intMinHeap{}}, nil
+}
+
+func (s *CalorieStats) Add(total int) {
+	if s.Count == 0 {
+		s.Min, s.Max = total, total
+	}
+	s.Count++
+	s.Sum += total
+	s.Min = Min(s.Min, total)
+	s.Max = Max(s.Max, total)
+	if s.KeepTotals {
+		s.totals = append(s.totals, total)
+		s.sorted = false
+	}
+
+	if len(s.top) < s.K {
+		heap.Push(&s.top, total)
+	} else if total > s.top[0] {
+		s.top[0] = total
+		heap.Fix(&s.top, 0)
+	}
+}
+
+// CollectCalories sums items of every elf in a single pass, only stats are kept in memory
+func CollectCalories(items CalorieItems, k int, keepTotals bool) (*CalorieStats, error) {
+	s, err := NewCalorieStats(k, keepTotals)
+	if err != nil {
+		return nil, err
+	}
+	sum, inGroup := 0, false
+	err = items(func(itm IntOrSpace) {
+		if !itm.space {
+			sum += itm.v
+			inGroup = true
+			return
+		}
+		if inGroup {
+			s.Add(sum)
+		}
+		sum, inGroup = 0, false
+	})
+	if err != nil {
+		return nil, err
+	}
+	// to handle the last elf
+	if inGroup {
+		s.Add(sum)
+	}
+	if s.Count == 0 {
+		return nil, fmt.Errorf("expected at least one elf")
+	}
+	return s, nil
+}
+
+// Top returns up to K biggest totals in descending order
+func (s *CalorieStats) Top() []int {
+	res := append([]int{}, s.top...)
+	sort.Sort(sort.Reverse(sort.IntSlice(res)))
+	return res
+}
+
+func (s *CalorieStats) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Sum) / float64(s.Count)
+}
+
+var errNoTotals = fmt.Errorf("totals aren't kept")
+
+func (s *CalorieStats) sortedTotals() []int {
+	if !s.sorted {
+		sort.Ints(s.totals)
+		s.sorted = true
+	}
+	return s.totals
+}
+
+// Percentile returns the smallest total which is greater or equal than p percent of totals (nearest rank method)
+func (s *CalorieStats) Percentile(p float64) (int, error) {
+	if !s.KeepTotals {
+		return 0, errNoTotals
+	}
+	if p <= 0 || p > 100 {
+		return 0, fmt.Errorf("expected percentile in (0, 100], got: %v", p)
+	}
+	if s.Count == 0 {
+		return 0, fmt.Errorf("no totals")
+	}
+	rank := int(math.Ceil(p / 100 * float64(s.Count)))
+	return s.sortedTotals()[rank-1], nil
+}
+
+// Median returns the middle total, or the mean of two middle totals for even count
+func (s *CalorieStats) Median() (float64, error) {
+	if !s.KeepTotals {
+		return 0, errNoTotals
+	}
+	totals := s.sortedTotals()
+	n := len(totals)
+	if n == 0 {
+		return 0, nil
+	}
+	if n%2 == 1 {
+		return float64(totals[n/2]), nil
+	}
+	return float64(totals[n/2-1]+totals[n/2]) / 2, nil
+}
+
+type HistogramBucket struct {
+	// both ends are included
+	From  int
+	To    int
+	Count int
+}
+
+// Histogram splits [Min, Max] into buckets of equal width
+func (s *CalorieStats) Histogram(buckets int) ([]HistogramBucket, error) {
+	if !s.KeepTotals {
+		return nil, errNoTotals
+	}
+	if buckets < 1 || s.Count == 0 {
+		return []HistogramBucket{}, nil
+	}
+	width := (s.Max-s.Min)/buckets + 1
+	res := []HistogramBucket{}
+	for from := s.Min; from <= s.Max; from += width {
+		res = append(res, HistogramBucket{From: from, To: from + width - 1})
+	}
+	for _, t := range s.totals {
+		res[(t-s.Min)/width].Count++
+	}
+	return res, nil
+}
+
+// Report returns summary of totals, median, percentiles and histogram are included only if totals are kept
+func (s *CalorieStats) Report(buckets int) string {
+	res := strings.Builder{}
+	fmt.Fprintf(&res, "elves: %v, total: %v, min: %v, max: %v\n", s.Count, s.Sum, s.Min, s.Max)
+	fmt.Fprintf(&res, "mean: %.1f\n", s.Mean())
+	fmt.Fprintf(&res, "top %v: %v\n", s.K, s.Top())
+	if !s.KeepTotals {
+		return res.String()
+	}
+	median, _ := s.Median()
+	fmt.Fprintf(&res, "median: %.1f\n", median)
+	for _, p := range []float64{25, 75, 90, 99} {
+		v, _ := s.Percentile(p)
+		fmt.Fprintf(&res, "p%v: %v\n", p, v)
+	}
+	hist, _ := s.Histogram(buckets)
+	maxCount := 0
+	for _, b := range hist {
+		maxCount = Max(maxCount, b.Count)
+	}
+	for _, b := range hist {
+		fmt.Fprintf(&res, "%6v - %6v %5v %v\n", b.From, b.To, b.Count, strings.Repeat("#", b.Count*40/maxCount))
+	}
+	return res.String()
+}
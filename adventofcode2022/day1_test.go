@@ -0,0 +1,94 @@
+package adventofcode2022_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+const d1ExampleData = "1000\n2000\n3000\n\n4000\n\n5000\n6000\n\n7000\n8000\n9000\n\n10000"
+
+var d1Example = linesReader(strings.Split(d1ExampleData, "\n"))
+
+func TestTask1(t *testing.T) {
+	res, err := adventofcode2022.Task1_1(d1Example, adventofcode2022.ToCalorieItems, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "Day 1 Part 1 result: 24000", res)
+
+	res, err = adventofcode2022.Task1_2(d1Example, adventofcode2022.ToCalorieItems, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "Day 1 Part 2 items: [24000 11000 10000], result: 45000", res)
+
+	// the file is read line by line
+	res, err = adventofcode2022.Task1_1(
+		&adventofcode2022.FileToStringsInputReader{Path: "../adventofcode2022/day1.data"},
+		adventofcode2022.ToCalorieItems,
+		false,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "Day 1 Part 1 result: 69836", res)
+
+	_, err = adventofcode2022.Task1_1(linesReader{"1000", "x"}, adventofcode2022.ToCalorieItems, false)
+	assert.NotNil(t, err)
+}
+
+func TestCalorieStats(t *testing.T) {
+	items, err := adventofcode2022.ToCalorieItems(d1Example)
+	assert.Nil(t, err)
+
+	stats, err := adventofcode2022.CollectCalories(items, 10, true)
+	assert.Nil(t, err)
+	// k is bigger than number of elves
+	assert.Equal(t, []int{24000, 11000, 10000, 6000, 4000}, stats.Top())
+	assert.Equal(t, 5, stats.Count)
+	assert.Equal(t, 55000, stats.Sum)
+	assert.Equal(t, 11000.0, stats.Mean())
+	median, err := stats.Median()
+	assert.Nil(t, err)
+	assert.Equal(t, 10000.0, median)
+	p, err := stats.Percentile(50)
+	assert.Nil(t, err)
+	assert.Equal(t, 10000, p)
+	p, err = stats.Percentile(100)
+	assert.Nil(t, err)
+	assert.Equal(t, 24000, p)
+	_, err = stats.Percentile(0)
+	assert.NotNil(t, err)
+
+	hist, err := stats.Histogram(3)
+	assert.Nil(t, err)
+	assert.Equal(t, []adventofcode2022.HistogramBucket{
+		{From: 4000, To: 10666, Count: 3},
+		{From: 10667, To: 17333, Count: 1},
+		{From: 17334, To: 24000, Count: 1},
+	}, hist)
+
+	stats.Add(1)
+	median, _ = stats.Median()
+	assert.Equal(t, 8000.0, median)
+	assert.Equal(t, 1, stats.Min)
+
+	_, err = adventofcode2022.NewCalorieStats(0, false)
+	assert.NotNil(t, err)
+}
+
+func TestCalorieStatsTopOnly(t *testing.T) {
+	items, err := adventofcode2022.ToCalorieItems(linesReader(strings.Split(d1ExampleData+"\n\n", "\n")))
+	assert.Nil(t, err)
+	stats, err := adventofcode2022.CollectCalories(items, 2, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{24000, 11000}, stats.Top())
+	assert.Equal(t, 5, stats.Count)
+	assert.Equal(t, 55000, stats.Sum)
+
+	// totals aren't kept, so only top-K and aggregates are available
+	_, err = stats.Percentile(50)
+	assert.NotNil(t, err)
+	_, err = stats.Median()
+	assert.NotNil(t, err)
+	_, err = stats.Histogram(3)
+	assert.NotNil(t, err)
+	assert.NotContains(t, stats.Report(3), "median")
+}
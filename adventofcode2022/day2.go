@@ -1,192 +1,372 @@
 package adventofcode2022
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 )
 
-// Rock  = 1 (A, X)
-// Paper = 2 (B, Y)
-// Scissors = 3 (C, Z)
-
-// Lost = 0
-// Draw = 3
-// Win = 6
+type Outcome int
 
 const (
-	winScore  int = 6
-	drawScore int = 3
-	loseScor  int = 0
+	Lose Outcome = iota
+	Draw
+	Win
 )
 
-type RPS int
+func (o Outcome) String() string {
+	switch o {
+	case Lose:
+		return "lose"
+	case Draw:
+		return "draw"
+	case Win:
+		return "win"
+	default:
+		return fmt.Sprintf("outcome(%d)", int(o))
+	}
+}
+
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *Outcome) UnmarshalText(text []byte) error {
+	for _, v := range []Outcome{Lose, Draw, Win} {
+		if v.String() == string(text) {
+			*o = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown outcome: %v, expected one of: lose, draw, win", string(text))
+}
+
+// Interpretation defines what the second column of the strategy guide means
+type Interpretation int
 
 const (
-	R RPS = iota
-	P
-	S
+	// second column is the hand to play, part 1
+	HandInterpretation Interpretation = iota
+	// second column is the outcome the round has to end with, part 2
+	OutcomeInterpretation
 )
 
-func (rps RPS) Score() int {
-	switch rps {
-	case R:
-		return 1
-	case P:
-		return 2
-	case S:
-		return 3
-	}
-	panic("unexpected behaviour")
-}
-
-func parseABS(s string) RPS {
-	switch s {
-	case "A":
-		return R
-	case "B":
-		return P
-	case "C":
-		return S
-	default:
-		panic(fmt.Errorf("unknown value %v in parseABS", s))
-	}
+type OutcomeScores struct {
+	Lose int `json:"lose"`
+	Draw int `json:"draw"`
+	Win  int `json:"win"`
 }
 
-func parseXYZ(s string) RPS {
-	switch s {
-	case "X":
-		return R
-	case "Y":
-		return P
-	case "Z":
-		return S
+func (s OutcomeScores) Score(o Outcome) int {
+	switch o {
+	case Win:
+		return s.Win
+	case Draw:
+		return s.Draw
 	default:
-		panic(fmt.Errorf("unknown value %v in parseXYZ", s))
+		return s.Lose
 	}
 }
 
-// RPS stands for: Rock, Paper, Scissors
-type TupleRPS struct {
-	l RPS
-	r RPS
+// Game is a rule set of rock paper scissors like game
+type Game struct {
+	Name  string   `json:"name"`
+	Hands []string `json:"hands"`
+	// hand -> hands it beats, every pair of different hands has to have exactly one winner
+	Beats         map[string][]string `json:"beats"`
+	HandScores    map[string]int      `json:"hand_scores"`
+	OutcomeScores OutcomeScores       `json:"outcome_scores"`
+	// symbol of the first column -> opponent's hand
+	Opponent map[string]string `json:"opponent"`
+	// symbol of the second column -> player's hand, used with HandInterpretation
+	Player map[string]string `json:"player"`
+	// symbol of the second column -> outcome, used with OutcomeInterpretation
+	Outcomes map[string]Outcome `json:"outcomes"`
+
+	beats map[string]map[string]bool
 }
 
-func ToTupleRPSArr(ir InputReader) ([]TupleRPS, error) {
-	content, err := ir.GetInput()
+// ReadGame reads game from JSON in the same format Game is serialized to
+func ReadGame(r io.Reader) (*Game, error) {
+	g := &Game{}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(g); err != nil {
+		return nil, fmt.Errorf("can't read game: %w", err)
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func LoadGame(path string) (*Game, error) {
+	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
+	return ReadGame(f)
+}
+
+// Validate checks that rules are complete and consistent, it has to be called after Game is modified
+func (g *Game) Validate() error {
+	if len(g.Hands) < 2 {
+		return fmt.Errorf("game %v: expected at least 2 hands, got: %v", g.Name, len(g.Hands))
+	}
+	known := map[string]bool{}
+	for _, h := range g.Hands {
+		if known[h] {
+			return fmt.Errorf("game %v: duplicate hand: %v", g.Name, h)
+		}
+		known[h] = true
+		if _, ok := g.HandScores[h]; !ok {
+			return fmt.Errorf("game %v: no score for hand: %v", g.Name, h)
+		}
+	}
 
-	tuples := []TupleRPS{}
-	for _, l := range content {
-		l = strings.TrimSpace(l)
-		if l != "" {
-			splt := strings.Split(l, " ")
-			if len(splt) != 2 {
-				return nil, fmt.Errorf("can't split line: %v properly with space separator", l)
+	beats := map[string]map[string]bool{}
+	for winner, losers := range g.Beats {
+		if !known[winner] {
+			return fmt.Errorf("game %v: unknown hand in beats: %v", g.Name, winner)
+		}
+		beats[winner] = map[string]bool{}
+		for _, l := range losers {
+			if !known[l] {
+				return fmt.Errorf("game %v: unknown hand in beats: %v", g.Name, l)
+			}
+			if l == winner {
+				return fmt.Errorf("game %v: %v can't beat itself", g.Name, l)
+			}
+			beats[winner][l] = true
+		}
+	}
+	for i, a := range g.Hands {
+		for _, b := range g.Hands[i+1:] {
+			if beats[a][b] == beats[b][a] {
+				return fmt.Errorf("game %v: expected exactly one winner between %v and %v", g.Name, a, b)
 			}
-			tuples = append(tuples, TupleRPS{parseABS(splt[0]), parseXYZ(splt[1])})
 		}
 	}
-	return tuples, nil
-}
 
-// Standard rules
-func Task2_1(ir InputReader, convertInput func(ir InputReader) ([]TupleRPS, error)) (string, error) {
-	source, err := convertInput(ir)
-	if err != nil {
-		return "", err
+	for name, m := range map[string]map[string]string{"opponent": g.Opponent, "player": g.Player} {
+		for sym, h := range m {
+			if !known[h] {
+				return fmt.Errorf("game %v: symbol %v of %v is mapped to unknown hand: %v", g.Name, sym, name, h)
+			}
+		}
+	}
+	for sym, o := range g.Outcomes {
+		if o < Lose || o > Win {
+			return fmt.Errorf("game %v: symbol %v is mapped to unknown outcome: %v", g.Name, sym, o)
+		}
 	}
+	g.beats = beats
+	return nil
+}
 
-	score := 0
+// Play returns outcome of the round for player
+func (g *Game) Play(opponent string, player string) Outcome {
+	switch {
+	case opponent == player:
+		return Draw
+	case g.beats[player][opponent]:
+		return Win
+	default:
+		return Lose
+	}
+}
 
-	for _, t := range source {
-		score += ruleset1Score(t.l, t.r)
+// HandFor returns hand which ends the round with the outcome, if there are several such hands
+// the one with the highest score is chosen, ties are resolved by the order of hands
+func (g *Game) HandFor(opponent string, outcome Outcome) (string, error) {
+	res := ""
+	for _, h := range g.Hands {
+		if g.Play(opponent, h) != outcome {
+			continue
+		}
+		if res == "" || g.HandScores[h] > g.HandScores[res] {
+			res = h
+		}
 	}
+	if res == "" {
+		return "", fmt.Errorf("game %v: no hand to %v against %v", g.Name, outcome, opponent)
+	}
+	return res, nil
+}
 
-	return fmt.Sprintf("result: %v", score), nil
+// Score returns score of the hand played and outcome of the round
+func (g *Game) Score(hand string, outcome Outcome) int {
+	return g.HandScores[hand] + g.OutcomeScores.Score(outcome)
 }
 
-func ruleset1Score(l RPS, r RPS) int {
-	if l == r {
-		return drawScore + r.Score()
-	} else if l == R && r == S {
-		return loseScor + r.Score()
-	} else if l == P && r == R {
-		return loseScor + r.Score()
-	} else if l == S && r == P {
-		return loseScor + r.Score()
-	} else {
-		return winScore + r.Score()
+// Resolve returns hands of both players according to symbols of the round
+func (g *Game) Resolve(r Round, interp Interpretation) (string, string, error) {
+	opponent, ok := g.Opponent[r.Opponent]
+	if !ok {
+		return "", "", fmt.Errorf("game %v: unknown opponent symbol: %v", g.Name, r.Opponent)
+	}
+	switch interp {
+	case HandInterpretation:
+		player, ok := g.Player[r.Second]
+		if !ok {
+			return "", "", fmt.Errorf("game %v: unknown player symbol: %v", g.Name, r.Second)
+		}
+		return opponent, player, nil
+	case OutcomeInterpretation:
+		outcome, ok := g.Outcomes[r.Second]
+		if !ok {
+			return "", "", fmt.Errorf("game %v: unknown outcome symbol: %v", g.Name, r.Second)
+		}
+		player, err := g.HandFor(opponent, outcome)
+		return opponent, player, err
+	default:
+		return "", "", fmt.Errorf("unknown interpretation: %v", interp)
 	}
 }
 
-func Task2_2(ir InputReader, convertInput func(ir InputReader) ([]TupleRPS, error)) (string, error) {
-	source, err := convertInput(ir)
+func (g *Game) RoundScore(r Round, interp Interpretation) (int, error) {
+	opponent, player, err := g.Resolve(r, interp)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
+	return g.Score(player, g.Play(opponent, player)), nil
+}
 
-	score := 0
+func (g *Game) TotalScore(rounds []Round, interp Interpretation) (int, error) {
+	total := 0
+	for i, r := range rounds {
+		score, err := g.RoundScore(r, interp)
+		if err != nil {
+			return 0, fmt.Errorf("round %v: %w", i+1, err)
+		}
+		total += score
+	}
+	return total, nil
+}
 
-	for _, t := range source {
-		score += ruleset2Score(t.l, t.r)
+// NewCyclicGame creates generalization of rock paper scissors for odd number of hands:
+// hand i beats hand j if (i - j) mod n is odd, so every hand beats half of the other hands.
+// Hands score 1..n, outcomes score 0, 3, 6. Opponent uses symbols from A, player uses symbols
+// which end with Z (X, Y, Z for 3 hands), X, Y, Z also mean lose, draw, win
+func NewCyclicGame(name string, hands []string) (*Game, error) {
+	n := len(hands)
+	if n < 3 || n%2 == 0 || n > 13 {
+		return nil, fmt.Errorf("expected odd number of hands from 3 to 13, got: %v", n)
+	}
+	g := &Game{
+		Name:          name,
+		Hands:         hands,
+		Beats:         map[string][]string{},
+		HandScores:    map[string]int{},
+		OutcomeScores: OutcomeScores{Lose: 0, Draw: 3, Win: 6},
+		Opponent:      map[string]string{},
+		Player:        map[string]string{},
+		Outcomes:      map[string]Outcome{"X": Lose, "Y": Draw, "Z": Win},
+	}
+	for i, h := range hands {
+		g.HandScores[h] = i + 1
+		g.Opponent[string(rune('A'+i))] = h
+		g.Player[string(rune('Z'-n+1+i))] = h
+		for j, other := range hands {
+			if ((i-j)%n+n)%n%2 == 1 {
+				g.Beats[h] = append(g.Beats[h], other)
+			}
+		}
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
 	}
+	return g, nil
+}
 
-	return fmt.Sprintf("result: %v", score), nil
+// RockPaperScissors is the game from the puzzle
+func RockPaperScissors() *Game {
+	g, _ := NewCyclicGame("rock paper scissors", []string{"rock", "paper", "scissors"})
+	return g
 }
 
-// X (R) means you need to lose
-// Y (P) means you need to end the round in a draw
-// Z (S) means you need to win
-func ruleset2Score(l RPS, r RPS) int {
-	switch r {
-	case R:
-		return loseTo(l).Score() + resultScore(r)
-	case P:
-		return drawWith(l).Score() + resultScore(r)
-	case S:
-		return winOf(l).Score() + resultScore(r)
-	}
-	panic("unexpected behaviour")
+func RockPaperScissorsLizardSpock() *Game {
+	g, _ := NewCyclicGame("rock paper scissors lizard spock", []string{"rock", "paper", "scissors", "spock", "lizard"})
+	return g
 }
 
-func resultScore(r RPS) int {
-	switch r {
-	case R:
-		return loseScor
-	case P:
-		return drawScore
-	case S:
-		return winScore
+// Round contains symbols from a line of the strategy guide, their meaning is defined by Game
+type Round struct {
+	Opponent string
+	Second   string
+}
+
+func ToRounds(ir InputReader) ([]Round, error) {
+	content, err := ir.GetInput()
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := []Round{}
+	for i, l := range content {
+		splt := strings.Fields(l)
+		if len(splt) == 0 {
+			continue
+		}
+		if len(splt) != 2 {
+			return nil, fmt.Errorf("line %v: expected 2 symbols separated by space, got: %v", i+1, l)
+		}
+		rounds = append(rounds, Round{Opponent: splt[0], Second: splt[1]})
 	}
-	panic("unexpected behaviour")
+	return rounds, nil
 }
 
-func winOf(sign RPS) RPS {
-	switch sign {
-	case R:
-		return P
-	case P:
-		return S
-	case S:
-		return R
+// loadRules returns game from the rule file, rock paper scissors if path is empty
+func loadRules(path string) (*Game, error) {
+	if path == "" {
+		return RockPaperScissors(), nil
 	}
-	panic("unexpected behaviour")
+	return LoadGame(path)
 }
 
-func drawWith(sign RPS) RPS {
-	return sign
+// Standard rules, rules is path to the rule file, empty for rock paper scissors
+func Task2_1(ir InputReader, convertInput func(ir InputReader) ([]Round, error), rules string, debug bool) (string, error) {
+	source, err := convertInput(ir)
+	if err != nil {
+		return "", err
+	}
+
+	game, err := loadRules(rules)
+	if err != nil {
+		return "", err
+	}
+	if debug {
+		debugD2Strategy(game, source, HandInterpretation, "debug_d2_1")
+	}
+	score, err := game.TotalScore(source, HandInterpretation)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("result: %v", score), nil
 }
 
-func loseTo(sign RPS) RPS {
-	switch sign {
-	case R:
-		return S
-	case P:
-		return R
-	case S:
-		return P
+// X means you need to lose
+// Y means you need to end the round in a draw
+// Z means you need to win
+func Task2_2(ir InputReader, convertInput func(ir InputReader) ([]Round, error), rules string, debug bool) (string, error) {
+	source, err := convertInput(ir)
+	if err != nil {
+		return "", err
+	}
+
+	game, err := loadRules(rules)
+	if err != nil {
+		return "", err
+	}
+	if debug {
+		debugD2Strategy(game, source, OutcomeInterpretation, "debug_d2_2")
+	}
+	score, err := game.TotalScore(source, OutcomeInterpretation)
+	if err != nil {
+		return "", err
 	}
-	panic("unexpected behaviour")
+	return fmt.Sprintf("result: %v", score), nil
 }
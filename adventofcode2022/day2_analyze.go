@@ -0,0 +1,282 @@
+package adventofcode2022
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SymbolMapping assigns meaning to symbols of the second column: hands or outcomes depending on interpretation
+type SymbolMapping struct {
+	Interpretation Interpretation
+	Symbols        []string
+	// Values[i] is the hand or the outcome (lose, draw, win) of Symbols[i]
+	Values []string
+	Score  int
+}
+
+func (m SymbolMapping) String() string {
+	parts := make([]string, len(m.Symbols))
+	for i, s := range m.Symbols {
+		parts[i] = s + "=" + m.Values[i]
+	}
+	return strings.Join(parts, " ")
+}
+
+// apply returns copy of the game where symbols of the second column have meanings from the mapping
+func (m SymbolMapping) apply(g *Game) (*Game, error) {
+	if len(m.Symbols) != len(m.Values) {
+		return nil, fmt.Errorf("mapping %v: expected the same number of symbols and values", m)
+	}
+	alt := *g
+	alt.Player = map[string]string{}
+	alt.Outcomes = map[string]Outcome{}
+	for i, sym := range m.Symbols {
+		switch m.Interpretation {
+		case HandInterpretation:
+			if _, ok := g.HandScores[m.Values[i]]; !ok {
+				return nil, fmt.Errorf("game %v: unknown hand: %v", g.Name, m.Values[i])
+			}
+			alt.Player[sym] = m.Values[i]
+		case OutcomeInterpretation:
+			var o Outcome
+			if err := o.UnmarshalText([]byte(m.Values[i])); err != nil {
+				return nil, err
+			}
+			alt.Outcomes[sym] = o
+		default:
+			return nil, fmt.Errorf("unknown interpretation: %v", m.Interpretation)
+		}
+	}
+	return &alt, nil
+}
+
+// StrategyAnalysis contains scores of all possible mappings of the second column, sorted from the best one
+type StrategyAnalysis struct {
+	Mappings []SymbolMapping
+}
+
+func (a StrategyAnalysis) Best() SymbolMapping {
+	return a.Mappings[0]
+}
+
+func (a StrategyAnalysis) Worst() SymbolMapping {
+	return a.Mappings[len(a.Mappings)-1]
+}
+
+// Distribution returns number of mappings for every score
+func (a StrategyAnalysis) Distribution() map[int]int {
+	res := map[int]int{}
+	for _, m := range a.Mappings {
+		res[m.Score]++
+	}
+	return res
+}
+
+func (a StrategyAnalysis) String() string {
+	res := strings.Builder{}
+	fmt.Fprintf(&res, "mappings: %v\n", len(a.Mappings))
+	fmt.Fprintf(&res, "best:  %v (%v)\n", a.Best().Score, a.Best())
+	fmt.Fprintf(&res, "worst: %v (%v)\n", a.Worst().Score, a.Worst())
+	dist := a.Distribution()
+	scores := make([]int, 0, len(dist))
+	for s := range dist {
+		scores = append(scores, s)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(scores)))
+	res.WriteString("distribution:\n")
+	for _, s := range scores {
+		fmt.Fprintf(&res, "  %v: %v\n", s, dist[s])
+	}
+	return res.String()
+}
+
+// injections calls visit with every sequence of k distinct indexes from [0, n)
+func injections(k int, n int, visit func(idx []int)) {
+	idx := make([]int, 0, k)
+	used := make([]bool, n)
+	var rec func()
+	rec = func() {
+		if len(idx) == k {
+			visit(idx)
+			return
+		}
+		for i := 0; i < n; i++ {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			idx = append(idx, i)
+			rec()
+			idx = idx[:len(idx)-1]
+			used[i] = false
+		}
+	}
+	rec()
+}
+
+// AnalyzeStrategy scores the strategy guide with every possible mapping of symbols of the second column
+// to hands or outcomes, different symbols always have different meanings. Mappings of the game are ignored,
+// only the mapping of the first column is used
+func (g *Game) AnalyzeStrategy(rounds []Round, interp Interpretation) (StrategyAnalysis, error) {
+	type pair struct{ opponent, second string }
+	counts := map[pair]int{}
+	symbolSet := map[string]bool{}
+	for i, r := range rounds {
+		if _, ok := g.Opponent[r.Opponent]; !ok {
+			return StrategyAnalysis{}, fmt.Errorf("round %v: game %v: unknown opponent symbol: %v", i+1, g.Name, r.Opponent)
+		}
+		counts[pair{r.Opponent, r.Second}]++
+		symbolSet[r.Second] = true
+	}
+	symbols := make([]string, 0, len(symbolSet))
+	for s := range symbolSet {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+
+	values := g.Hands
+	if interp == OutcomeInterpretation {
+		values = []string{Lose.String(), Draw.String(), Win.String()}
+	}
+	if len(symbols) > len(values) {
+		return StrategyAnalysis{}, fmt.Errorf("game %v: %v symbols can't be mapped to %v values", g.Name, len(symbols), len(values))
+	}
+
+	alt := *g
+	res := StrategyAnalysis{}
+	var err error
+	injections(len(symbols), len(values), func(idx []int) {
+		if err != nil {
+			return
+		}
+		m := SymbolMapping{Interpretation: interp, Symbols: symbols, Values: make([]string, len(idx))}
+		alt.Player = map[string]string{}
+		alt.Outcomes = map[string]Outcome{}
+		for i, v := range idx {
+			m.Values[i] = values[v]
+			alt.Player[symbols[i]] = values[v]
+			alt.Outcomes[symbols[i]] = Outcome(v)
+		}
+		for p, c := range counts {
+			score, e := alt.RoundScore(Round{p.opponent, p.second}, interp)
+			if e != nil {
+				err = e
+				return
+			}
+			m.Score += score * c
+		}
+		res.Mappings = append(res.Mappings, m)
+	})
+	if err != nil {
+		return StrategyAnalysis{}, err
+	}
+	sort.SliceStable(res.Mappings, func(i, j int) bool { return res.Mappings[i].Score > res.Mappings[j].Score })
+	return res, nil
+}
+
+type RoundResult struct {
+	Round        int
+	Opponent     string
+	Player       string
+	Outcome      Outcome
+	HandScore    int
+	OutcomeScore int
+	Total        int
+}
+
+// Breakdown returns result of every round with scores accumulated from the first round,
+// symbols of the second column mean what mapping says, nil mapping means mapping of the game
+func (g *Game) Breakdown(rounds []Round, interp Interpretation, mapping *SymbolMapping) ([]RoundResult, error) {
+	if mapping != nil {
+		if mapping.Interpretation != interp {
+			return nil, fmt.Errorf("mapping %v: expected interpretation %v, got: %v", mapping, interp, mapping.Interpretation)
+		}
+		alt, err := mapping.apply(g)
+		if err != nil {
+			return nil, err
+		}
+		g = alt
+	}
+	res := make([]RoundResult, 0, len(rounds))
+	total := 0
+	for i, r := range rounds {
+		opponent, player, err := g.Resolve(r, interp)
+		if err != nil {
+			return nil, fmt.Errorf("round %v: %w", i+1, err)
+		}
+		outcome := g.Play(opponent, player)
+		total += g.Score(player, outcome)
+		res = append(res, RoundResult{
+			Round:        i + 1,
+			Opponent:     opponent,
+			Player:       player,
+			Outcome:      outcome,
+			HandScore:    g.HandScores[player],
+			OutcomeScore: g.OutcomeScores.Score(outcome),
+			Total:        total,
+		})
+	}
+	return res, nil
+}
+
+func WriteBreakdownCSV(writer io.Writer, results []RoundResult) error {
+	w := csv.NewWriter(writer)
+	if err := w.Write([]string{"round", "opponent", "player", "outcome", "hand_score", "outcome_score", "total"}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		rec := []string{
+			strconv.Itoa(r.Round),
+			r.Opponent,
+			r.Player,
+			r.Outcome.String(),
+			strconv.Itoa(r.HandScore),
+			strconv.Itoa(r.OutcomeScore),
+			strconv.Itoa(r.Total),
+		}
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// debugD2Strategy prints analysis of all mappings and writes breakdowns of the game's mapping
+// and of the best one to prefix_breakdown.csv and prefix_best_breakdown.csv
+func debugD2Strategy(g *Game, rounds []Round, interp Interpretation, prefix string) {
+	analysis, err := g.AnalyzeStrategy(rounds, interp)
+	if err != nil {
+		fmt.Printf("can't print debug: %v\n", err)
+		return
+	}
+	fmt.Print(analysis)
+
+	best := analysis.Best()
+	for name, mapping := range map[string]*SymbolMapping{
+		prefix + "_breakdown.csv":      nil,
+		prefix + "_best_breakdown.csv": &best,
+	} {
+		if err := writeBreakdownFile(g, rounds, interp, mapping, name); err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+		}
+	}
+}
+
+func writeBreakdownFile(g *Game, rounds []Round, interp Interpretation, mapping *SymbolMapping, name string) error {
+	results, err := g.Breakdown(rounds, interp, mapping)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return WriteBreakdownCSV(f, results)
+}
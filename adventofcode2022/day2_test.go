@@ -0,0 +1,145 @@
+package adventofcode2022_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTask2(t *testing.T) {
+	example := linesReader{"A Y", "B X", "C Z"}
+	res, err := adventofcode2022.Task2_1(example, adventofcode2022.ToRounds, "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "result: 15", res)
+
+	res, err = adventofcode2022.Task2_2(example, adventofcode2022.ToRounds, "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "result: 12", res)
+
+	_, err = adventofcode2022.Task2_1(linesReader{"A Y", "D X"}, adventofcode2022.ToRounds, "", false)
+	assert.EqualError(t, err, "round 2: game rock paper scissors: unknown opponent symbol: D")
+
+	// D is spock, W is paper: paper disproves spock
+	res, err = adventofcode2022.Task2_1(linesReader{"D W"}, adventofcode2022.ToRounds, "../adventofcode2022/day2_rpsls.json", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "result: 8", res)
+	_, err = adventofcode2022.Task2_2(example, adventofcode2022.ToRounds, "no_such_rules.json", false)
+	assert.NotNil(t, err)
+}
+
+func TestLoadGame(t *testing.T) {
+	loaded, err := adventofcode2022.LoadGame("../adventofcode2022/day2_rpsls.json")
+	assert.Nil(t, err)
+	builtin := adventofcode2022.RockPaperScissorsLizardSpock()
+
+	for _, opp := range "ABCDE" {
+		for _, second := range "VWXYZ" {
+			r := adventofcode2022.Round{Opponent: string(opp), Second: string(second)}
+			for _, interp := range []adventofcode2022.Interpretation{adventofcode2022.HandInterpretation, adventofcode2022.OutcomeInterpretation} {
+				if interp == adventofcode2022.OutcomeInterpretation && second < 'X' {
+					continue
+				}
+				expected, err := builtin.RoundScore(r, interp)
+				assert.Nil(t, err)
+				actual, err := loaded.RoundScore(r, interp)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, actual, r)
+			}
+		}
+	}
+
+	// spock vaporizes rock, spock smashes scissors
+	assert.Equal(t, adventofcode2022.Win, loaded.Play("rock", "spock"))
+	assert.Equal(t, adventofcode2022.Lose, loaded.Play("spock", "scissors"))
+	// lizard (5) and paper (2) both lose to scissors
+	hand, err := loaded.HandFor("scissors", adventofcode2022.Lose)
+	assert.Nil(t, err)
+	assert.Equal(t, "lizard", hand)
+}
+
+func TestGameValidation(t *testing.T) {
+	cases := map[string]string{
+		"unknown field": `{"name": "g", "foo": 1}`,
+		"one hand":      `{"name": "g", "hands": ["a"], "hand_scores": {"a": 1}}`,
+		"no winner": `{"name": "g", "hands": ["a", "b", "c"], "hand_scores": {"a": 1, "b": 2, "c": 3},
+			"beats": {"a": ["b"], "b": ["c"]}}`,
+		"both win": `{"name": "g", "hands": ["a", "b"], "hand_scores": {"a": 1, "b": 2},
+			"beats": {"a": ["b"], "b": ["a"]}}`,
+		"unknown outcome": `{"name": "g", "hands": ["a", "b"], "hand_scores": {"a": 1, "b": 2},
+			"beats": {"a": ["b"]}, "outcomes": {"X": "tie"}}`,
+		"unknown hand": `{"name": "g", "hands": ["a", "b"], "hand_scores": {"a": 1, "b": 2},
+			"beats": {"a": ["b"]}, "opponent": {"A": "c"}}`,
+	}
+	for name, c := range cases {
+		_, err := adventofcode2022.ReadGame(strings.NewReader(c))
+		assert.NotNil(t, err, name)
+	}
+
+	_, err := adventofcode2022.NewCyclicGame("even", []string{"a", "b", "c", "d"})
+	assert.NotNil(t, err)
+	g, err := adventofcode2022.NewCyclicGame("seven", []string{"a", "b", "c", "d", "e", "f", "g"})
+	assert.Nil(t, err)
+	// every hand beats 3 others
+	for _, h := range g.Hands {
+		assert.Equal(t, 3, len(g.Beats[h]))
+	}
+	score, err := g.RoundScore(adventofcode2022.Round{Opponent: "A", Second: "U"}, adventofcode2022.HandInterpretation)
+	assert.Nil(t, err)
+	// U is the second hand, it beats the first one
+	assert.Equal(t, 2+6, score)
+}
+
+func TestAnalyzeStrategy(t *testing.T) {
+	rounds, err := adventofcode2022.ToRounds(linesReader{"A Y", "B X", "C Z"})
+	assert.Nil(t, err)
+	game := adventofcode2022.RockPaperScissors()
+
+	a, err := game.AnalyzeStrategy(rounds, adventofcode2022.HandInterpretation)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(a.Mappings))
+	// X=rock Y=paper Z=scissors is the puzzle's mapping, but X=scissors Y=paper Z=rock wins every round
+	assert.Equal(t, "X=scissors Y=paper Z=rock", a.Best().String())
+	assert.Equal(t, 24, a.Best().Score)
+	assert.Equal(t, "X=rock Y=scissors Z=paper", a.Worst().String())
+	assert.Equal(t, 6, a.Worst().Score)
+	total := 0
+	for _, cnt := range a.Distribution() {
+		total += cnt
+	}
+	assert.Equal(t, 6, total)
+
+	a, err = game.AnalyzeStrategy(rounds, adventofcode2022.OutcomeInterpretation)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(a.Mappings))
+	assert.Equal(t, "X=win Y=lose Z=draw", a.Best().String())
+	// the puzzle's mapping is the worst one for the example
+	assert.Equal(t, "X=lose Y=draw Z=win", a.Worst().String())
+	assert.Equal(t, map[int]int{18: 1, 15: 4, 12: 1}, a.Distribution())
+
+	results, err := game.Breakdown(rounds, adventofcode2022.OutcomeInterpretation, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, adventofcode2022.RoundResult{Round: 3, Opponent: "scissors", Player: "rock", Outcome: adventofcode2022.Win, HandScore: 1, OutcomeScore: 6, Total: 12}, results[2])
+	buf := bytes.Buffer{}
+	assert.Nil(t, adventofcode2022.WriteBreakdownCSV(&buf, results))
+	assert.Equal(t, "round,opponent,player,outcome,hand_score,outcome_score,total\n"+
+		"1,rock,rock,draw,1,3,4\n"+
+		"2,paper,rock,lose,1,0,5\n"+
+		"3,scissors,rock,win,1,6,12\n", buf.String())
+
+	// breakdown of the best mapping sums up to its score
+	best := a.Best()
+	results, err = game.Breakdown(rounds, adventofcode2022.OutcomeInterpretation, &best)
+	assert.Nil(t, err)
+	assert.Equal(t, best.Score, results[len(results)-1].Total)
+	assert.Equal(t, adventofcode2022.RoundResult{Round: 1, Opponent: "rock", Player: "scissors", Outcome: adventofcode2022.Lose, HandScore: 3, OutcomeScore: 0, Total: 3}, results[0])
+
+	_, err = game.Breakdown(rounds, adventofcode2022.HandInterpretation, &best)
+	assert.NotNil(t, err)
+	best.Values = []string{"rock", "paper", "lizard"}
+	best.Interpretation = adventofcode2022.HandInterpretation
+	_, err = game.Breakdown(rounds, adventofcode2022.HandInterpretation, &best)
+	assert.NotNil(t, err)
+}
@@ -2,187 +2,201 @@ package adventofcode2022
 
 import (
 	"fmt"
-	"math"
+	"math/bits"
+	"strings"
 )
 
-type TupleIntArr struct {
-	l []int
-	r []int
+// Alphabet defines items and their priorities: the first item has priority 1, the second 2, etc.
+type Alphabet struct {
+	items      []rune
+	priorities map[rune]int
 }
 
-func ToTupleIntArr(ir InputReader) ([]TupleIntArr, error) {
-	lines, err := ir.GetInput()
-	if err != nil {
-		return nil, err
-	}
+// DefaultAlphabet is the alphabet from the puzzle: a-z have priorities 1-26, A-Z 27-52
+var DefaultAlphabet, _ = NewAlphabet("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 
-	converted := []TupleIntArr{}
-	for _, line := range lines {
-		// according to task condition, it should be even number of symbols in line
-		middleIdx := len(line) / 2
-		fst := line[:middleIdx]
-		scnd := line[middleIdx:]
-		converted = append(converted, TupleIntArr{
-			l: stringToPriorityArr(fst),
-			r: stringToPriorityArr(scnd),
-		})
+// NewAlphabet creates alphabet of up to 64 distinct items, so every set of items fits into ItemSet
+func NewAlphabet(items string) (Alphabet, error) {
+	a := Alphabet{priorities: map[rune]int{}}
+	for _, r := range items {
+		if _, ok := a.priorities[r]; ok {
+			return Alphabet{}, fmt.Errorf("duplicate item in alphabet: %c", r)
+		}
+		a.items = append(a.items, r)
+		a.priorities[r] = len(a.items)
 	}
+	if len(a.items) == 0 || len(a.items) > 64 {
+		return Alphabet{}, fmt.Errorf("expected from 1 to 64 items in alphabet, got: %v", len(a.items))
+	}
+	return a, nil
+}
+
+func (a Alphabet) Priority(item rune) (int, bool) {
+	p, ok := a.priorities[item]
+	return p, ok
+}
 
-	return converted, nil
+func (a Alphabet) Item(priority int) rune {
+	return a.items[priority-1]
 }
 
-func stringToPriorityArr(s string) []int {
-	res := []int{}
+// ItemSet returns set of items of s, error is returned for items which aren't in the alphabet
+func (a Alphabet) ItemSet(s string) (ItemSet, error) {
+	var set ItemSet
 	for _, r := range s {
-		res = append(res, getPriority(r))
+		p, ok := a.priorities[r]
+		if !ok {
+			return 0, fmt.Errorf("unknown item: %c", r)
+		}
+		set |= 1 << (p - 1)
 	}
-	return res
+	return set, nil
 }
 
-func getPriority(r rune) int {
-	if r >= 97 {
-		return int(math.Abs(float64(97-int(r)))) + 1
-	} else {
-		return int(math.Abs(float64(65-r))) + 1 + 26
+// Format returns items of the set in order of priorities
+func (a Alphabet) Format(set ItemSet) string {
+	res := strings.Builder{}
+	for _, p := range set.Priorities() {
+		res.WriteRune(a.Item(p))
 	}
+	return res.String()
 }
 
-// Solution in this task is only for group of two sequences of items
-// General solution can be found in Task3_2
-func Task3_1(ir InputReader, convertInput func(ir InputReader) ([]TupleIntArr, error)) (string, error) {
-	data, err := convertInput(ir)
-	if err != nil {
-		return "", err
-	}
-	commons := []int{}
-	for _, t := range data {
-		lefts := [53]int{}
-		rights := [53]int{}
-		found := false
-		//populate temp storage, in case we found common element finish eagerly
-		for i := 0; i < len(t.l); i++ {
-			if rights[t.l[i]] == 1 {
-				commons = append(commons, t.l[i])
-				found = true
-				break
-			} else {
-				lefts[t.l[i]] = 1
-			}
+// ItemSet is a bitset, bit i is set if the item with priority i+1 is in the set
+type ItemSet uint64
 
-			if lefts[t.r[i]] == 1 {
-				commons = append(commons, t.r[i])
-				found = true
-				break
-			} else {
-				rights[t.r[i]] = 1
-			}
-		}
+func (s ItemSet) Len() int {
+	return bits.OnesCount64(uint64(s))
+}
 
-		if found {
-			continue
-		}
-		//if has't finished eagerly, one more iteration
-		for i := 0; i < len(t.l); i++ {
-			if rights[t.l[i]] == 1 {
-				commons = append(commons, t.l[i])
-				break
-			}
-			if lefts[t.r[i]] == 1 {
-				commons = append(commons, t.r[i])
-				break
-			}
-		}
+// Priorities returns priorities of items in ascending order
+func (s ItemSet) Priorities() []int {
+	res := make([]int, 0, s.Len())
+	for rest := uint64(s); rest != 0; rest &= rest - 1 {
+		res = append(res, bits.TrailingZeros64(rest)+1)
 	}
+	return res
+}
 
+func (s ItemSet) Sum() int {
 	sum := 0
-	for _, i := range commons {
-		sum += i
+	for _, p := range s.Priorities() {
+		sum += p
 	}
+	return sum
+}
 
-	return fmt.Sprintf("result: %v", sum), nil
+// RucksackLayout describes how items are compared: every group of GroupSize rucksacks
+// (consecutive lines) is checked for items shared by all compartments of all rucksacks of the group,
+// every rucksack is split into Compartments equal parts
+type RucksackLayout struct {
+	Alphabet     Alphabet
+	Compartments int
+	GroupSize    int
 }
 
-func To3DArray(ir InputReader, groups int) ([][][]int, error) {
-	lines, err := ir.GetInput()
-	if err != nil {
-		return nil, err
-	}
+var (
+	// CompartmentsLayout is used by part 1: items shared by both compartments of a rucksack
+	CompartmentsLayout = RucksackLayout{Alphabet: DefaultAlphabet, Compartments: 2, GroupSize: 1}
+	// BadgesLayout is used by part 2: items shared by three rucksacks
+	BadgesLayout = RucksackLayout{Alphabet: DefaultAlphabet, Compartments: 1, GroupSize: 3}
+)
 
-	if len(lines)%groups != 0 {
-		return nil, fmt.Errorf("bad input, %v is not dividable by %v", len(lines), groups)
+// SharedItems returns all items shared within every group
+func (l RucksackLayout) SharedItems(rucksacks []string) ([]ItemSet, error) {
+	if l.Compartments < 1 || l.GroupSize < 1 {
+		return nil, fmt.Errorf("expected positive number of compartments and group size, got: %v, %v", l.Compartments, l.GroupSize)
+	}
+	if len(rucksacks)%l.GroupSize != 0 {
+		return nil, fmt.Errorf("bad input, %v is not dividable by %v", len(rucksacks), l.GroupSize)
 	}
 
-	converted := [][][]int{}
-	groupN := 0
-	for i := 0; i < len(lines); i += groups {
-		converted = append(converted, [][]int{})
-		for j := 0; j < groups; j++ {
-			converted[groupN] = append(converted[groupN], stringToPriorityArr(lines[i+j]))
+	res := []ItemSet{}
+	for i := 0; i < len(rucksacks); i += l.GroupSize {
+		shared := ^ItemSet(0)
+		for j, r := range rucksacks[i : i+l.GroupSize] {
+			// items of the alphabet can be multibyte runes
+			items := []rune(r)
+			if len(items)%l.Compartments != 0 {
+				return nil, fmt.Errorf("rucksack %v: %v items can't be split into %v compartments", i+j+1, len(items), l.Compartments)
+			}
+			size := len(items) / l.Compartments
+			for c := 0; c < l.Compartments; c++ {
+				set, err := l.Alphabet.ItemSet(string(items[c*size : (c+1)*size]))
+				if err != nil {
+					return nil, fmt.Errorf("rucksack %v: %w", i+j+1, err)
+				}
+				shared &= set
+			}
 		}
-		groupN++
+		res = append(res, shared)
 	}
-
-	return converted, nil
+	return res, nil
 }
 
-func storageIdx(bpNum int, item int) int {
-	return bpNum*52 + item - 1
-}
-
-// data structure is follwing:
-//
-// [][][]int is:
-// groups[
-//
-//	backpups_in_group[
-//		items_in_backpack[
-//			item_value
-//			]
-//		]
-//	]
-//
-// ]
-func Task3_2(ir InputReader, convertInput func(ir InputReader, groups int) ([][][]int, error)) (string, error) {
-	groupSize := 3
-	data, err := convertInput(ir, 3)
+// SharedItem returns priority of the only shared item of every group,
+// error lists all groups which share no items or several items
+func (l RucksackLayout) SharedItem(rucksacks []string) ([]int, error) {
+	sets, err := l.SharedItems(rucksacks)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	commons := []int{}
-	for _, group := range data {
-		storage := map[int]bool{}
-		for i, bp := range group {
-			for _, item := range bp {
-				storage[storageIdx(i, item)] = true
-			}
+	res := make([]int, len(sets))
+	invalid := []string{}
+	for i, s := range sets {
+		switch s.Len() {
+		case 1:
+			res[i] = s.Priorities()[0]
+		case 0:
+			invalid = append(invalid, fmt.Sprintf("group %v: expected exactly one shared item, got none", i+1))
+		default:
+			invalid = append(invalid, fmt.Sprintf("group %v: expected exactly one shared item, got: %v", i+1, l.Alphabet.Format(s)))
 		}
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%v", strings.Join(invalid, "; "))
+	}
+	return res, nil
+}
 
-		//It should be enough to check only first backpack in group,
-		//because it will contain common item for all groups
-		check_bp := group[0]
-		for _, item := range check_bp {
-			found := true
-			for i := 0; i < groupSize; i++ {
-				if !found {
-					break
-				}
-				_, ok := storage[storageIdx(i, item)]
-				if !ok {
-					found = false
-					break
-				}
-			}
-			if found {
-				commons = append(commons, item)
-				break
-			}
+func ToRucksacks(ir InputReader) ([]string, error) {
+	lines, err := ir.GetInput()
+	if err != nil {
+		return nil, err
+	}
+	res := []string{}
+	for _, l := range lines {
+		if l = strings.TrimSpace(l); l != "" {
+			res = append(res, l)
 		}
 	}
+	return res, nil
+}
 
+func sumSharedItems(rucksacks []string, layout RucksackLayout) (string, error) {
+	shared, err := layout.SharedItem(rucksacks)
+	if err != nil {
+		return "", err
+	}
 	sum := 0
-	for _, i := range commons {
-		sum += i
+	for _, p := range shared {
+		sum += p
 	}
 	return fmt.Sprintf("result: %v", sum), nil
 }
+
+func Task3_1(ir InputReader, convertInput func(ir InputReader) ([]string, error)) (string, error) {
+	data, err := convertInput(ir)
+	if err != nil {
+		return "", err
+	}
+	return sumSharedItems(data, CompartmentsLayout)
+}
+
+func Task3_2(ir InputReader, convertInput func(ir InputReader) ([]string, error)) (string, error) {
+	data, err := convertInput(ir)
+	if err != nil {
+		return "", err
+	}
+	return sumSharedItems(data, BadgesLayout)
+}
@@ -0,0 +1,73 @@
+package adventofcode2022_test
+
+import (
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+var d3Example = linesReader{
+	"vJrwpWtwJgWrhcsFMMfFFhFp",
+	"jqHRNqRjqzjGDLGLrsFMfFZSrLrFZsSL",
+	"PmmdzqPrVvPwwTWBwg",
+	"wMqvLMZHhHMvwLHjbvcjnnSBnvTQFn",
+	"ttgJtRGJQctTZtZT",
+	"CrZsJsPPZsGzwwsLwLmpwMDw",
+}
+
+func TestTask3(t *testing.T) {
+	res, err := adventofcode2022.Task3_1(d3Example, adventofcode2022.ToRucksacks)
+	assert.Nil(t, err)
+	assert.Equal(t, "result: 157", res)
+
+	res, err = adventofcode2022.Task3_2(d3Example, adventofcode2022.ToRucksacks)
+	assert.Nil(t, err)
+	assert.Equal(t, "result: 70", res)
+}
+
+func TestRucksackLayout(t *testing.T) {
+	alphabet, err := adventofcode2022.NewAlphabet("0123456789")
+	assert.Nil(t, err)
+	layout := adventofcode2022.RucksackLayout{Alphabet: alphabet, Compartments: 3, GroupSize: 2}
+
+	shared, err := layout.SharedItems([]string{"123134152", "415516617"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(shared))
+	assert.Equal(t, "1", alphabet.Format(shared[0]))
+
+	shared, err = layout.SharedItems([]string{"121212", "212121"})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2, 3}, shared[0].Priorities())
+	assert.Equal(t, 5, shared[0].Sum())
+
+	_, err = layout.SharedItem([]string{"121212", "212121"})
+	assert.EqualError(t, err, "group 1: expected exactly one shared item, got: 12")
+	_, err = layout.SharedItem([]string{"123", "456"})
+	assert.EqualError(t, err, "group 1: expected exactly one shared item, got none")
+	// every invalid group is reported
+	_, err = layout.SharedItem([]string{"121212", "212121", "111111", "111111", "123", "456"})
+	assert.EqualError(t, err, "group 1: expected exactly one shared item, got: 12; group 3: expected exactly one shared item, got none")
+	_, err = layout.SharedItems([]string{"1234", "123"})
+	assert.NotNil(t, err)
+	_, err = layout.SharedItems([]string{"abc", "123"})
+	assert.NotNil(t, err)
+	_, err = layout.SharedItems([]string{"123"})
+	assert.NotNil(t, err)
+
+	// all 52 default items fit into a single set
+	set, err := adventofcode2022.DefaultAlphabet.ItemSet("azAZ")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 26, 27, 52}, set.Priorities())
+
+	// multibyte items are split by runes, not bytes
+	greek, err := adventofcode2022.NewAlphabet("αβab")
+	assert.Nil(t, err)
+	shared, err = adventofcode2022.RucksackLayout{Alphabet: greek, Compartments: 2, GroupSize: 1}.SharedItems([]string{"αaab", "βαbα"})
+	assert.Nil(t, err)
+	assert.Equal(t, "a", greek.Format(shared[0]))
+	assert.Equal(t, "α", greek.Format(shared[1]))
+
+	_, err = adventofcode2022.NewAlphabet("aa")
+	assert.NotNil(t, err)
+}
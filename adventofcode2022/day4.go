@@ -10,11 +10,40 @@ type TupleSegment struct {
 	_1 Segment
 	_2 Segment
 }
+
+// Segment is a range of sections, both ends are included
 type Segment struct {
 	l int
 	r int
 }
 
+func NewSegment(l int, r int) (Segment, error) {
+	if l > r {
+		return Segment{}, fmt.Errorf("expected start <= end, got: %v-%v", l, r)
+	}
+	return Segment{l: l, r: r}, nil
+}
+
+func NewTupleSegment(fst Segment, snd Segment) TupleSegment {
+	return TupleSegment{_1: fst, _2: snd}
+}
+
+func (s Segment) Start() int {
+	return s.l
+}
+
+func (s Segment) End() int {
+	return s.r
+}
+
+func (s Segment) Len() int {
+	return s.r - s.l + 1
+}
+
+func (s Segment) String() string {
+	return fmt.Sprintf("%v-%v", s.l, s.r)
+}
+
 func ToTupleSegment(ir InputReader) ([]TupleSegment, error) {
 	lines, err := ir.GetInput()
 	if err != nil {
@@ -53,12 +82,15 @@ func ToTupleSegment(ir InputReader) ([]TupleSegment, error) {
 		if err != nil {
 			return nil, err
 		}
-		converted = append(converted,
-			TupleSegment{
-				_1: Segment{l: s1L, r: s1R},
-				_2: Segment{l: s2L, r: s2R},
-			},
-		)
+		fst, err := NewSegment(s1L, s1R)
+		if err != nil {
+			return nil, err
+		}
+		snd, err := NewSegment(s2L, s2R)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, NewTupleSegment(fst, snd))
 	}
 
 	return converted, nil
@@ -69,17 +101,7 @@ func Task4_1(ir InputReader, convInput func(InputReader) ([]TupleSegment, error)
 	if err != nil {
 		return "", err
 	}
-
-	count := 0
-
-	for _, s := range data {
-		if s._1.l-s._2.l <= 0 && s._1.r-s._2.r >= 0 ||
-			s._1.l-s._2.l >= 0 && s._1.r-s._2.r <= 0 {
-			count++
-		}
-	}
-
-	return fmt.Sprintf("result: %v", count), nil
+	return fmt.Sprintf("result: %v", CountRelations(data, FullyContained)), nil
 }
 
 func Task4_2(ir InputReader, convInput func(InputReader) ([]TupleSegment, error)) (string, error) {
@@ -87,15 +109,5 @@ func Task4_2(ir InputReader, convInput func(InputReader) ([]TupleSegment, error)
 	if err != nil {
 		return "", err
 	}
-
-	count := 0
-
-	for _, s := range data {
-		if s._1.l <= s._2.l && s._1.r >= s._2.l ||
-			s._2.l <= s._1.l && s._2.r >= s._1.l {
-			count++
-		}
-	}
-
-	return fmt.Sprintf("result: %v", count), nil
+	return fmt.Sprintf("result: %v", CountRelations(data, Overlapping)), nil
 }
@@ -0,0 +1,156 @@
+package adventofcode2022
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// AllenRelation is one of 13 relations of Allen's interval algebra
+type AllenRelation int
+
+const (
+	RelBefore AllenRelation = iota
+	RelMeets
+	RelOverlaps
+	RelStarts
+	RelDuring
+	RelFinishes
+	RelEquals
+	RelFinishedBy
+	RelContains
+	RelStartedBy
+	RelOverlappedBy
+	RelMetBy
+	RelAfter
+)
+
+// AllenRelations contains all relations, every relation is followed by its inverse in the reverse order
+var AllenRelations = []AllenRelation{
+	RelBefore, RelMeets, RelOverlaps, RelStarts, RelDuring, RelFinishes, RelEquals,
+	RelFinishedBy, RelContains, RelStartedBy, RelOverlappedBy, RelMetBy, RelAfter,
+}
+
+var allenNames = []string{
+	"before", "meets", "overlaps", "starts", "during", "finishes", "equals",
+	"finished-by", "contains", "started-by", "overlapped-by", "met-by", "after",
+}
+
+func (r AllenRelation) String() string {
+	if r < RelBefore || r > RelAfter {
+		return fmt.Sprintf("relation(%d)", int(r))
+	}
+	return allenNames[r]
+}
+
+// Inverse returns relation of b to a if r is relation of a to b
+func (r AllenRelation) Inverse() AllenRelation {
+	return RelAfter - r
+}
+
+// Relation classifies relation of s to o. Segments consist of whole sections,
+// so s meets o if o starts right after the last section of s, they don't share any section
+func (s Segment) Relation(o Segment) AllenRelation {
+	// half-open intervals [start, end)
+	as, ae := s.l, s.r+1
+	bs, be := o.l, o.r+1
+	switch {
+	case ae < bs:
+		return RelBefore
+	case ae == bs:
+		return RelMeets
+	case be < as:
+		return RelAfter
+	case be == as:
+		return RelMetBy
+	case as == bs && ae == be:
+		return RelEquals
+	case as == bs && ae < be:
+		return RelStarts
+	case as == bs:
+		return RelStartedBy
+	case ae == be && as > bs:
+		return RelFinishes
+	case ae == be:
+		return RelFinishedBy
+	case as > bs && ae < be:
+		return RelDuring
+	case as < bs && ae > be:
+		return RelContains
+	case as < bs:
+		return RelOverlaps
+	default:
+		return RelOverlappedBy
+	}
+}
+
+func (t TupleSegment) Relation() AllenRelation {
+	return t._1.Relation(t._2)
+}
+
+// RelationSet is a bitset of relations
+type RelationSet uint16
+
+func NewRelationSet(rels ...AllenRelation) RelationSet {
+	var s RelationSet
+	for _, r := range rels {
+		s |= 1 << r
+	}
+	return s
+}
+
+func (s RelationSet) Has(r AllenRelation) bool {
+	return s&(1<<r) != 0
+}
+
+var (
+	// FullyContained relations: one segment contains the other one, part 1
+	FullyContained = NewRelationSet(RelStarts, RelDuring, RelFinishes, RelEquals, RelFinishedBy, RelContains, RelStartedBy)
+	// Overlapping relations: segments share at least one section, part 2
+	Overlapping = FullyContained | NewRelationSet(RelOverlaps, RelOverlappedBy)
+)
+
+// CountRelations returns number of pairs whose relation is in the set
+func CountRelations(pairs []TupleSegment, set RelationSet) int {
+	count := 0
+	for _, p := range pairs {
+		if set.Has(p.Relation()) {
+			count++
+		}
+	}
+	return count
+}
+
+// RelationHistogram returns number of pairs for every relation
+func RelationHistogram(pairs []TupleSegment) map[AllenRelation]int {
+	res := map[AllenRelation]int{}
+	for _, p := range pairs {
+		res[p.Relation()]++
+	}
+	return res
+}
+
+// WriteRelationReport prints count of every relation with a bar scaled to width characters
+func WriteRelationReport(w io.Writer, hist map[AllenRelation]int, width int) error {
+	total, maxCount := 0, 0
+	for _, c := range hist {
+		total += c
+		maxCount = Max(maxCount, c)
+	}
+	for _, r := range AllenRelations {
+		c := hist[r]
+		bar := 0
+		if maxCount > 0 {
+			bar = c * width / maxCount
+		}
+		share := 0.0
+		if total > 0 {
+			share = float64(c) * 100 / float64(total)
+		}
+		if _, err := fmt.Fprintf(w, "%-14v %6v %5.1f%% %v\n", r, c, share, strings.Repeat("#", bar)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%-14v %6v\n", "total", total)
+	return err
+}
@@ -0,0 +1,222 @@
+package adventofcode2022
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Segments returns assignments of all elves
+func Segments(pairs []TupleSegment) []Segment {
+	res := make([]Segment, 0, len(pairs)*2)
+	for _, p := range pairs {
+		res = append(res, p._1, p._2)
+	}
+	return res
+}
+
+func sortedSegments(segs []Segment) []Segment {
+	sorted := append([]Segment{}, segs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].l != sorted[j].l {
+			return sorted[i].l < sorted[j].l
+		}
+		return sorted[i].r < sorted[j].r
+	})
+	return sorted
+}
+
+// MergeSegments returns union of segments as disjoint sorted segments,
+// segments which touch each other (like 1-2 and 3-4) are merged
+func MergeSegments(segs []Segment) []Segment {
+	res := []Segment{}
+	for _, s := range sortedSegments(segs) {
+		if len(res) > 0 && s.l <= res[len(res)-1].r+1 {
+			res[len(res)-1].r = Max(res[len(res)-1].r, s.r)
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+// Unassigned returns sections of within which aren't covered by any segment
+func Unassigned(segs []Segment, within Segment) []Segment {
+	res := []Segment{}
+	next := within.l
+	for _, s := range MergeSegments(segs) {
+		if s.r < next {
+			continue
+		}
+		if s.l > within.r {
+			break
+		}
+		if s.l > next {
+			res = append(res, Segment{l: next, r: s.l - 1})
+		}
+		next = s.r + 1
+	}
+	if next <= within.r {
+		res = append(res, Segment{l: next, r: within.r})
+	}
+	return res
+}
+
+// MaxOverlap returns the maximum number of segments sharing a section and ranges of sections where it's reached
+func MaxOverlap(segs []Segment) (int, []Segment) {
+	type event struct {
+		pos   int
+		delta int
+	}
+	events := make([]event, 0, len(segs)*2)
+	for _, s := range segs {
+		events = append(events, event{s.l, 1}, event{s.r + 1, -1})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].pos < events[j].pos })
+
+	maxCnt := 0
+	ranges := []Segment{}
+	cur := 0
+	for i := 0; i < len(events); {
+		pos := events[i].pos
+		for ; i < len(events) && events[i].pos == pos; i++ {
+			cur += events[i].delta
+		}
+		if cur == 0 || i == len(events) {
+			continue
+		}
+		// cur is the overlap of sections [pos, next event)
+		rng := Segment{l: pos, r: events[i].pos - 1}
+		switch {
+		case cur > maxCnt:
+			maxCnt = cur
+			ranges = []Segment{rng}
+		case cur == maxCnt:
+			ranges = append(ranges, rng)
+		}
+	}
+	return maxCnt, MergeSegments(ranges)
+}
+
+// MinimalCover returns the smallest subset of segments which covers the same sections as all segments do.
+// Every part of the union is covered greedily: among segments starting in already covered area
+// the one which ends the farthest is chosen, it's known to be optimal for intervals
+func MinimalCover(segs []Segment) []Segment {
+	sorted := sortedSegments(segs)
+	res := []Segment{}
+	i := 0
+	for i < len(sorted) {
+		// start of the next part of the union
+		covered := sorted[i].l - 1
+		for i < len(sorted) && sorted[i].l <= covered+1 {
+			best := -1
+			for ; i < len(sorted) && sorted[i].l <= covered+1; i++ {
+				if sorted[i].r > covered && (best == -1 || sorted[i].r > sorted[best].r) {
+					best = i
+				}
+			}
+			if best == -1 {
+				continue
+			}
+			res = append(res, sorted[best])
+			covered = sorted[best].r
+		}
+	}
+	return res
+}
+
+// CoverageReport describes sections of Range only, assignments are clipped to it
+type CoverageReport struct {
+	Range Segment
+	// number of assignments sharing at least one section with Range
+	Assignments  int
+	Covered      int
+	Union        []Segment
+	Unassigned   []Segment
+	MaxOverlap   int
+	MaxOverlapAt []Segment
+	// original assignments, only their parts within Range are taken into account
+	MinimalCover []Segment
+}
+
+// clipSegments returns parts of segments within the range, segments outside of it are dropped.
+// orig maps every part to the first segment it's taken from
+func clipSegments(segs []Segment, within Segment) (clipped []Segment, orig map[Segment]Segment) {
+	clipped = []Segment{}
+	orig = map[Segment]Segment{}
+	for _, s := range segs {
+		if s.r < within.l || s.l > within.r {
+			continue
+		}
+		c := Segment{l: Max(s.l, within.l), r: Min(s.r, within.r)}
+		clipped = append(clipped, c)
+		if _, ok := orig[c]; !ok {
+			orig[c] = s
+		}
+	}
+	return clipped, orig
+}
+
+// AnalyzeCoverage checks how segments cover sections of within
+func AnalyzeCoverage(segs []Segment, within Segment) CoverageReport {
+	clipped, orig := clipSegments(segs, within)
+	unassigned := Unassigned(clipped, within)
+	uncovered := 0
+	for _, s := range unassigned {
+		uncovered += s.Len()
+	}
+	maxOverlap, at := MaxOverlap(clipped)
+	cover := MinimalCover(clipped)
+	for i, s := range cover {
+		cover[i] = orig[s]
+	}
+	return CoverageReport{
+		Range:        within,
+		Assignments:  len(clipped),
+		Covered:      within.Len() - uncovered,
+		Union:        MergeSegments(clipped),
+		Unassigned:   unassigned,
+		MaxOverlap:   maxOverlap,
+		MaxOverlapAt: at,
+		MinimalCover: cover,
+	}
+}
+
+// SegmentsRange returns the smallest segment which contains all segments
+func SegmentsRange(segs []Segment) (Segment, error) {
+	if len(segs) == 0 {
+		return Segment{}, fmt.Errorf("expected at least one segment")
+	}
+	res := segs[0]
+	for _, s := range segs {
+		res.l = Min(res.l, s.l)
+		res.r = Max(res.r, s.r)
+	}
+	return res, nil
+}
+
+func joinSegments(segs []Segment) string {
+	if len(segs) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(segs))
+	for i, s := range segs {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+func (r CoverageReport) WriteReport(w io.Writer) error {
+	lines := []string{
+		fmt.Sprintf("sections:      %v (%v)", r.Range, r.Range.Len()),
+		fmt.Sprintf("assignments:   %v", r.Assignments),
+		fmt.Sprintf("covered:       %v", r.Covered),
+		fmt.Sprintf("union:         %v", joinSegments(r.Union)),
+		fmt.Sprintf("unassigned:    %v", joinSegments(r.Unassigned)),
+		fmt.Sprintf("max overlap:   %v at %v", r.MaxOverlap, joinSegments(r.MaxOverlapAt)),
+		fmt.Sprintf("minimal cover: %v assignments: %v", len(r.MinimalCover), joinSegments(r.MinimalCover)),
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
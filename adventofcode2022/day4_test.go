@@ -0,0 +1,107 @@
+package adventofcode2022_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+var d4Example = linesReader{"2-4,6-8", "2-3,4-5", "5-7,7-9", "2-8,3-7", "6-6,4-6", "2-6,4-8"}
+
+func TestTask4(t *testing.T) {
+	res, err := adventofcode2022.Task4_1(d4Example, adventofcode2022.ToTupleSegment)
+	assert.Nil(t, err)
+	assert.Equal(t, "result: 2", res)
+
+	res, err = adventofcode2022.Task4_2(d4Example, adventofcode2022.ToTupleSegment)
+	assert.Nil(t, err)
+	assert.Equal(t, "result: 4", res)
+
+	_, err = adventofcode2022.ToTupleSegment(linesReader{"4-2,1-1"})
+	assert.NotNil(t, err)
+}
+
+func TestAllenRelations(t *testing.T) {
+	seg := func(l int, r int) adventofcode2022.Segment {
+		s, err := adventofcode2022.NewSegment(l, r)
+		assert.Nil(t, err)
+		return s
+	}
+	base := seg(3, 6)
+	cases := []struct {
+		other    adventofcode2022.Segment
+		relation adventofcode2022.AllenRelation
+	}{
+		{seg(8, 9), adventofcode2022.RelBefore},
+		{seg(7, 9), adventofcode2022.RelMeets},
+		{seg(5, 9), adventofcode2022.RelOverlaps},
+		{seg(3, 9), adventofcode2022.RelStarts},
+		{seg(1, 9), adventofcode2022.RelDuring},
+		{seg(1, 6), adventofcode2022.RelFinishes},
+		{seg(3, 6), adventofcode2022.RelEquals},
+		{seg(5, 6), adventofcode2022.RelFinishedBy},
+		{seg(4, 5), adventofcode2022.RelContains},
+		{seg(3, 4), adventofcode2022.RelStartedBy},
+		{seg(1, 4), adventofcode2022.RelOverlappedBy},
+		{seg(1, 2), adventofcode2022.RelMetBy},
+		{seg(0, 1), adventofcode2022.RelAfter},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.relation, base.Relation(c.other), c.other.String())
+		assert.Equal(t, c.relation.Inverse(), c.other.Relation(base), c.other.String())
+	}
+
+	pairs, err := adventofcode2022.ToTupleSegment(d4Example)
+	assert.Nil(t, err)
+	hist := adventofcode2022.RelationHistogram(pairs)
+	assert.Equal(t, map[adventofcode2022.AllenRelation]int{
+		adventofcode2022.RelBefore:   1,
+		adventofcode2022.RelMeets:    1,
+		adventofcode2022.RelOverlaps: 2,
+		adventofcode2022.RelContains: 1,
+		adventofcode2022.RelFinishes: 1,
+	}, hist)
+
+	buf := bytes.Buffer{}
+	assert.Nil(t, adventofcode2022.WriteRelationReport(&buf, hist, 10))
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, "overlaps            2  33.3% ##########", lines[2])
+	assert.Equal(t, "total               6", lines[13])
+}
+
+func TestCoverage(t *testing.T) {
+	pairs, err := adventofcode2022.ToTupleSegment(linesReader{"2-4,6-8", "3-5,12-12", "6-6,7-10"})
+	assert.Nil(t, err)
+	segs := adventofcode2022.Segments(pairs)
+
+	within, err := adventofcode2022.NewSegment(1, 14)
+	assert.Nil(t, err)
+	report := adventofcode2022.AnalyzeCoverage(segs, within)
+	buf := bytes.Buffer{}
+	assert.Nil(t, report.WriteReport(&buf))
+	assert.Equal(t, `sections:      1-14 (14)
+assignments:   6
+covered:       10
+union:         2-10 12-12
+unassigned:    1-1 11-11 13-14
+max overlap:   2 at 3-4 6-8
+minimal cover: 5 assignments: 2-4 3-5 6-8 7-10 12-12
+`, buf.String())
+
+	// assignments are clipped to the range: 12-12 is outside of it, 3-5 and 6-8 alone cover the whole range
+	within, err = adventofcode2022.NewSegment(3, 7)
+	assert.Nil(t, err)
+	buf.Reset()
+	assert.Nil(t, adventofcode2022.AnalyzeCoverage(segs, within).WriteReport(&buf))
+	assert.Equal(t, `sections:      3-7 (5)
+assignments:   5
+covered:       5
+union:         3-7
+unassigned:    -
+max overlap:   2 at 3-4 6-7
+minimal cover: 2 assignments: 3-5 6-8
+`, buf.String())
+}
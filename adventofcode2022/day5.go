@@ -1,12 +1,13 @@
 package adventofcode2022
 
 import (
-	"reflect"
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
+// Stacks are keyed by 0-based index, stack number from the puzzle is index+1
 type Stacks map[int]Stack
 
 type Stack struct {
@@ -14,6 +15,37 @@ type Stack struct {
 	boxes []string
 }
 
+func (s Stack) Top() string {
+	if len(s.boxes) == 0 {
+		return ""
+	}
+	return s.boxes[0]
+}
+
+func (s Stack) Len() int {
+	return len(s.boxes)
+}
+
+// Copy returns stacks which don't share boxes with the original ones
+func (s Stacks) Copy() Stacks {
+	res := Stacks{}
+	for k, v := range s {
+		boxes := make([]string, len(v.boxes))
+		copy(boxes, v.boxes)
+		res[k] = Stack{boxes: boxes}
+	}
+	return res
+}
+
+// Tops returns top boxes of all stacks in order, empty stacks are skipped
+func (s Stacks) Tops() string {
+	res := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		res.WriteString(s[i].Top())
+	}
+	return res.String()
+}
+
 type Moves []Move
 
 type Move struct {
@@ -22,16 +54,16 @@ type Move struct {
 	to    int
 }
 
-func transpose(data [][]string) [][]string {
-	res := make([][]string, len(data[0]))
-	for i := 0; i < len(data); i++ {
-		for j := 0; j < len(data[i]); j++ {
-			res[j] = append(res[j], data[i][j])
-		}
-	}
-	return res
+func (mv Move) String() string {
+	return fmt.Sprintf("move %v from %v to %v", mv.count, mv.from+1, mv.to+1)
 }
 
+var (
+	crateRe = regexp.MustCompile(`\[([^\]]+)\]`)
+	moveRe  = regexp.MustCompile(`^move (\d+) from (\d+) to (\d+)$`)
+	stackRe = regexp.MustCompile(`\d+`)
+)
+
 func ToStacksAndMoves(ir InputReader) (Stacks, Moves, error) {
 	content, err := ir.GetInput()
 	if err != nil {
@@ -39,127 +71,113 @@ func ToStacksAndMoves(ir InputReader) (Stacks, Moves, error) {
 	}
 
 	idx := 0
-	line := "-1"
-	horizontalBoxes := [][]string{}
-	for {
-		line = content[idx]
-		finish, _ := regexp.Match("\\s{1,}\\d", []byte(line))
-		if finish {
-			break
-		}
-		boxes := []string{}
-		ll := len(line)
-		for i := 1; i < ll; i += 4 {
-			boxes = append(boxes, string(line[i]))
-		}
-		horizontalBoxes = append(horizontalBoxes, boxes)
+	for idx < len(content) && strings.TrimSpace(content[idx]) != "" {
 		idx++
 	}
-
-	vertBoxes := transpose(horizontalBoxes)
-	stacks := Stacks{}
-	for i := 0; i < len(vertBoxes); i++ {
-		boxes := []string{}
-		topStack := ""
-		for _, b := range vertBoxes[i] {
-			if b == " " {
-				continue
-			}
-			if topStack == "" {
-				topStack = b
-			}
-			boxes = append(boxes, b)
-		}
-		stacks[i] = Stack{boxes: boxes}
+	stacks, err := ParseStacks(content[:idx])
+	if err != nil {
+		return nil, nil, err
 	}
 
-	idx += 2 // to jump over empty line for future reading
-
-	foundRe := regexp.MustCompile("move (\\d+) from (\\d+) to (\\d+)")
 	moves := Moves{}
-	for i := idx; i < len(content); i++ {
-		line := content[i]
-		found := foundRe.FindStringSubmatch(line)[1:]
-		count, _ := strconv.Atoi(found[0])
-		from, _ := strconv.Atoi(found[1])
-		to, _ := strconv.Atoi(found[2])
-		mv := Move{count: count, from: from - 1, to: to - 1}
+	for i := idx + 1; i < len(content); i++ {
+		if strings.TrimSpace(content[i]) == "" {
+			continue
+		}
+		mv, err := ParseMove(content[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %v: %w", i+1, err)
+		}
+		if mv.from >= len(stacks) || mv.to >= len(stacks) {
+			return nil, nil, fmt.Errorf("line %v: there are only %v stacks: %v", i+1, len(stacks), mv)
+		}
 		moves = append(moves, mv)
 	}
 
 	return stacks, moves, nil
-
 }
 
-func reverse(s interface{}) {
-	n := reflect.ValueOf(s).Len()
-	swap := reflect.Swapper(s)
-	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
-		swap(i, j)
+// ParseStacks reads drawing of stacks, the last line contains numbers of stacks.
+// Every crate belongs to the stack whose number is the closest to the crate horizontally,
+// so numbers with more than one digit and crates with longer labels are supported
+func ParseStacks(lines []string) (Stacks, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("expected drawing of stacks, got empty input")
 	}
-}
-
-func Task5_1(ir InputReader, cnvrtInpt func(InputReader) (Stacks, Moves, error)) (string, error) {
-	stacks, moves, err := cnvrtInpt(ir)
-	if err != nil {
-		return "", err
+	numbersLine := lines[len(lines)-1]
+	numbers := stackRe.FindAllStringIndex(numbersLine, -1)
+	if len(numbers) == 0 {
+		return nil, fmt.Errorf("expected numbers of stacks, got: %v", numbersLine)
+	}
+	centers := make([]int, len(numbers))
+	for i, loc := range numbers {
+		n, _ := strconv.Atoi(numbersLine[loc[0]:loc[1]])
+		if n != i+1 {
+			return nil, fmt.Errorf("expected stack number %v, got: %v", i+1, n)
+		}
+		// doubled to avoid fractions
+		centers[i] = loc[0] + loc[1]
 	}
 
-	for _, mv := range moves {
-		from := stacks[mv.from]
-		to := stacks[mv.to]
-
-		tomove := make([]string, mv.count)
-		copy(tomove, from.boxes[:mv.count])
-		reverse(tomove)
-		tomove = append(tomove, to.boxes...)
-		to.boxes = tomove
-		stacks[mv.to] = to
-
-		if mv.count >= len(from.boxes) {
-			from.boxes = []string{}
-		} else {
-			from.boxes = from.boxes[mv.count:]
+	stacks := Stacks{}
+	for i := range numbers {
+		stacks[i] = Stack{boxes: []string{}}
+	}
+	for row, line := range lines[:len(lines)-1] {
+		if strings.TrimSpace(crateRe.ReplaceAllString(line, "")) != "" {
+			return nil, fmt.Errorf("row %v: expected crates in format [X], got: %v", row+1, line)
+		}
+		for _, loc := range crateRe.FindAllStringSubmatchIndex(line, -1) {
+			center := loc[0] + loc[1]
+			closest := 0
+			for i, c := range centers {
+				if Abs(c-center) < Abs(centers[closest]-center) {
+					closest = i
+				}
+			}
+			s := stacks[closest]
+			s.boxes = append(s.boxes, line[loc[2]:loc[3]])
+			stacks[closest] = s
 		}
-		stacks[mv.from] = from
 	}
+	return stacks, nil
+}
 
-	res := strings.Builder{}
-	for i := 0; i < len(stacks); i++ {
-		res.WriteString(stacks[i].boxes[0])
+func ParseMove(line string) (Move, error) {
+	found := moveRe.FindStringSubmatch(strings.TrimSpace(line))
+	if found == nil {
+		return Move{}, fmt.Errorf("expected format: [move <count> from <stack> to <stack>], got: %v", line)
 	}
+	count, _ := strconv.Atoi(found[1])
+	from, _ := strconv.Atoi(found[2])
+	to, _ := strconv.Atoi(found[3])
+	if from < 1 || to < 1 {
+		return Move{}, fmt.Errorf("stacks are numbered from 1, got: %v", line)
+	}
+	return Move{count: count, from: from - 1, to: to - 1}, nil
+}
 
-	return res.String(), nil
+func runCrane(stacks Stacks, moves Moves, crane Crane) (string, error) {
+	for i, mv := range moves {
+		if err := crane.Apply(stacks, mv); err != nil {
+			return "", fmt.Errorf("move %v [%v]: %w", i+1, mv, err)
+		}
+	}
+	return stacks.Tops(), nil
 }
 
-func Task5_2(ir InputReader, cnvrtInpt func(InputReader) (Stacks, Moves, error)) (string, error) {
+func Task5_1(ir InputReader, cnvrtInpt func(InputReader) (Stacks, Moves, error)) (string, error) {
 	stacks, moves, err := cnvrtInpt(ir)
 	if err != nil {
 		return "", err
 	}
+	return runCrane(stacks, moves, CrateMover9000)
+}
 
-	for _, mv := range moves {
-		from := stacks[mv.from]
-		to := stacks[mv.to]
-
-		tomove := make([]string, mv.count)
-		copy(tomove, from.boxes[:mv.count])
-		tomove = append(tomove, to.boxes...)
-		to.boxes = tomove
-		stacks[mv.to] = to
-
-		if mv.count >= len(from.boxes) {
-			from.boxes = []string{}
-		} else {
-			from.boxes = from.boxes[mv.count:]
-		}
-		stacks[mv.from] = from
-	}
-
-	res := strings.Builder{}
-	for i := 0; i < len(stacks); i++ {
-		res.WriteString(stacks[i].boxes[0])
+func Task5_2(ir InputReader, cnvrtInpt func(InputReader) (Stacks, Moves, error)) (string, error) {
+	stacks, moves, err := cnvrtInpt(ir)
+	if err != nil {
+		return "", err
 	}
-
-	return res.String(), nil
+	return runCrane(stacks, moves, CrateMover9001)
 }
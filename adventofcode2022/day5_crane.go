@@ -0,0 +1,177 @@
+package adventofcode2022
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Crane rearranges crates according to a move, stacks are modified in place
+type Crane interface {
+	Apply(stacks Stacks, mv Move) error
+}
+
+// BatchCrane picks up to Batch crates at once, order of crates within a batch is preserved,
+// Batch <= 0 means there's no limit
+type BatchCrane struct {
+	Batch int
+}
+
+var (
+	// CrateMover9000 moves crates one at a time
+	CrateMover9000 Crane = BatchCrane{Batch: 1}
+	// CrateMover9001 moves all crates at once
+	CrateMover9001 Crane = BatchCrane{}
+)
+
+func (c BatchCrane) Apply(stacks Stacks, mv Move) error {
+	from, ok := stacks[mv.from]
+	if !ok {
+		return fmt.Errorf("stack %v doesn't exist", mv.from+1)
+	}
+	to, ok := stacks[mv.to]
+	if !ok {
+		return fmt.Errorf("stack %v doesn't exist", mv.to+1)
+	}
+	if mv.count <= 0 {
+		return fmt.Errorf("expected positive number of crates to move, got: %v", mv.count)
+	}
+	if mv.count > len(from.boxes) {
+		return fmt.Errorf("can't move %v crates, stack %v has only %v", mv.count, mv.from+1, len(from.boxes))
+	}
+	if mv.from == mv.to {
+		return nil
+	}
+
+	batch := c.Batch
+	if batch <= 0 || batch > mv.count {
+		batch = mv.count
+	}
+	// every next batch is put on top of the previous one
+	moved := make([]string, 0, mv.count+len(to.boxes))
+	for start := mv.count - mv.count%batch; start >= 0; start -= batch {
+		if start == mv.count {
+			continue
+		}
+		moved = append(moved, from.boxes[start:Min(start+batch, mv.count)]...)
+	}
+	to.boxes = append(moved, to.boxes...)
+	from.boxes = from.boxes[mv.count:]
+	stacks[mv.from] = from
+	stacks[mv.to] = to
+	return nil
+}
+
+type stacksUndo struct {
+	from      int
+	to        int
+	fromBoxes []string
+	toBoxes   []string
+}
+
+// CrateReplay applies moves one by one and keeps enough history to undo them
+type CrateReplay struct {
+	Crane  Crane
+	Moves  Moves
+	Stacks Stacks
+	// number of applied moves
+	Step int
+
+	history []stacksUndo
+}
+
+func NewCrateReplay(stacks Stacks, moves Moves, crane Crane) *CrateReplay {
+	return &CrateReplay{
+		Crane:  crane,
+		Moves:  moves,
+		Stacks: stacks.Copy(),
+	}
+}
+
+func (r *CrateReplay) Done() bool {
+	return r.Step >= len(r.Moves)
+}
+
+// Next applies the next move, false is returned if all moves are already applied
+func (r *CrateReplay) Next() (bool, error) {
+	if r.Done() {
+		return false, nil
+	}
+	mv := r.Moves[r.Step]
+	undo := stacksUndo{
+		from:      mv.from,
+		to:        mv.to,
+		fromBoxes: append([]string{}, r.Stacks[mv.from].boxes...),
+		toBoxes:   append([]string{}, r.Stacks[mv.to].boxes...),
+	}
+	if err := r.Crane.Apply(r.Stacks, mv); err != nil {
+		return false, fmt.Errorf("move %v [%v]: %w", r.Step+1, mv, err)
+	}
+	r.history = append(r.history, undo)
+	r.Step++
+	return true, nil
+}
+
+// Undo reverts the last applied move, false is returned if there's nothing to undo
+func (r *CrateReplay) Undo() bool {
+	if len(r.history) == 0 {
+		return false
+	}
+	last := r.history[len(r.history)-1]
+	r.history = r.history[:len(r.history)-1]
+	r.Stacks[last.from] = Stack{boxes: last.fromBoxes}
+	r.Stacks[last.to] = Stack{boxes: last.toBoxes}
+	r.Step--
+	return true
+}
+
+// Seek applies or reverts moves until step moves are applied
+func (r *CrateReplay) Seek(step int) error {
+	if step < 0 || step > len(r.Moves) {
+		return fmt.Errorf("expected step from 0 to %v, got: %v", len(r.Moves), step)
+	}
+	for r.Step > step {
+		r.Undo()
+	}
+	for r.Step < step {
+		if _, err := r.Next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String draws stacks the same way as puzzle input does, so the result can be parsed back by ParseStacks
+func (s Stacks) String() string {
+	width := 3
+	height := 0
+	for i := 0; i < len(s); i++ {
+		for _, b := range s[i].boxes {
+			width = Max(width, len(b)+2)
+		}
+		width = Max(width, len(fmt.Sprint(i+1)))
+		height = Max(height, len(s[i].boxes))
+	}
+
+	res := strings.Builder{}
+	for row := height; row > 0; row-- {
+		cells := make([]string, len(s))
+		for i := range cells {
+			boxes := s[i].boxes
+			cell := ""
+			if row <= len(boxes) {
+				cell = "[" + boxes[len(boxes)-row] + "]"
+			}
+			cells[i] = fmt.Sprintf("%-*v", width, cell)
+		}
+		res.WriteString(strings.TrimRight(strings.Join(cells, " "), " "))
+		res.WriteString("\n")
+	}
+	cells := make([]string, len(s))
+	for i := range cells {
+		num := fmt.Sprint(i + 1)
+		left := (width - len(num)) / 2
+		cells[i] = fmt.Sprintf("%*v%-*v", left, "", width-left, num)
+	}
+	res.WriteString(strings.Join(cells, " "))
+	return res.String()
+}
@@ -0,0 +1,126 @@
+package adventofcode2022
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// movePermutation returns p where p[j] is the position (from the top) a crate had on the source stack
+// if after moving count crates by crane it's at position j on the destination stack
+func movePermutation(crane Crane, count int) ([]int, error) {
+	boxes := make([]string, count)
+	for i := range boxes {
+		boxes[i] = strconv.Itoa(i)
+	}
+	stacks := Stacks{0: Stack{boxes: boxes}, 1: Stack{boxes: []string{}}}
+	if err := crane.Apply(stacks, Move{count: count, from: 0, to: 1}); err != nil {
+		return nil, err
+	}
+	moved := stacks[1].boxes
+	if len(moved) != count {
+		return nil, fmt.Errorf("crane moved %v crates instead of %v", len(moved), count)
+	}
+	p := make([]int, count)
+	seen := make([]bool, count)
+	for j, b := range moved {
+		i, _ := strconv.Atoi(b)
+		if seen[i] {
+			return nil, fmt.Errorf("crane duplicated crates")
+		}
+		seen[i] = true
+		p[j] = i
+	}
+	return p, nil
+}
+
+// reverseMoves undoes moves starting from the last one, if there aren't enough crates to undo a move
+// and fill isn't nil, missing crates are created by fill at the bottom of the stack
+func reverseMoves(final Stacks, moves Moves, crane Crane, fill func() string) (Stacks, error) {
+	stacks := final.Copy()
+	perms := map[int][]int{}
+	for i := len(moves) - 1; i >= 0; i-- {
+		mv := moves[i]
+		from, ok := stacks[mv.from]
+		if !ok {
+			return nil, fmt.Errorf("move %v [%v]: stack %v doesn't exist", i+1, mv, mv.from+1)
+		}
+		to, ok := stacks[mv.to]
+		if !ok {
+			return nil, fmt.Errorf("move %v [%v]: stack %v doesn't exist", i+1, mv, mv.to+1)
+		}
+		if mv.count <= 0 {
+			return nil, fmt.Errorf("move %v [%v]: expected positive number of crates to move, got: %v", i+1, mv, mv.count)
+		}
+		for len(to.boxes) < mv.count && fill != nil {
+			to.boxes = append(to.boxes, fill())
+		}
+		if len(to.boxes) < mv.count {
+			return nil, fmt.Errorf("move %v [%v]: stack %v has only %v crates", i+1, mv, mv.to+1, len(to.boxes))
+		}
+		if mv.from == mv.to {
+			stacks[mv.to] = to
+			continue
+		}
+
+		p, ok := perms[mv.count]
+		if !ok {
+			var err error
+			if p, err = movePermutation(crane, mv.count); err != nil {
+				return nil, fmt.Errorf("move %v [%v]: %w", i+1, mv, err)
+			}
+			perms[mv.count] = p
+		}
+		orig := make([]string, mv.count, mv.count+len(from.boxes))
+		for j, b := range to.boxes[:mv.count] {
+			orig[p[j]] = b
+		}
+		from.boxes = append(orig, from.boxes...)
+		to.boxes = to.boxes[mv.count:]
+		stacks[mv.from] = from
+		stacks[mv.to] = to
+	}
+	return stacks, nil
+}
+
+// InitialStacks computes stacks which turn into final after moves are applied by crane
+func InitialStacks(final Stacks, moves Moves, crane Crane) (Stacks, error) {
+	return reverseMoves(final, moves, crane, nil)
+}
+
+// InitialStacksForTops computes stacks which have tops on top after moves are applied by crane,
+// every letter of tops is the top crate of the corresponding stack. Crates which aren't defined by tops
+// are labeled with letters of filler in a loop
+func InitialStacksForTops(tops string, moves Moves, crane Crane, filler string) (Stacks, error) {
+	if filler == "" {
+		return nil, fmt.Errorf("expected non empty filler")
+	}
+	final := Stacks{}
+	for i, c := range []rune(tops) {
+		final[i] = Stack{boxes: []string{string(c)}}
+	}
+	for _, mv := range moves {
+		if mv.from >= len(final) || mv.to >= len(final) {
+			return nil, fmt.Errorf("%v: there are only %v stacks", mv, len(final))
+		}
+	}
+	letters := []rune(filler)
+	n := 0
+	return reverseMoves(final, moves, crane, func() string {
+		n++
+		return string(letters[(n-1)%len(letters)])
+	})
+}
+
+// WritePuzzleInput writes stacks and moves in the format of the puzzle input, so it can be read by ToStacksAndMoves
+func WritePuzzleInput(w io.Writer, stacks Stacks, moves Moves) error {
+	if _, err := fmt.Fprintf(w, "%v\n\n", stacks); err != nil {
+		return err
+	}
+	for _, mv := range moves {
+		if _, err := fmt.Fprintln(w, mv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package adventofcode2022_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+var d5Example = linesReader(strings.Split(`    [D]    
+[N] [C]    
+[Z] [M] [P]
+ 1   2   3 
+
+move 1 from 2 to 1
+move 3 from 1 to 3
+move 2 from 2 to 1
+move 1 from 1 to 2`, "\n"))
+
+func TestTask5(t *testing.T) {
+	res, err := adventofcode2022.Task5_1(d5Example, adventofcode2022.ToStacksAndMoves)
+	assert.Nil(t, err)
+	assert.Equal(t, "CMZ", res)
+
+	res, err = adventofcode2022.Task5_2(d5Example, adventofcode2022.ToStacksAndMoves)
+	assert.Nil(t, err)
+	assert.Equal(t, "MCD", res)
+}
+
+func TestStacksRender(t *testing.T) {
+	lines, err := (&adventofcode2022.FileToStringsInputReader{Path: "../adventofcode2022/day5.data"}).GetInput()
+	assert.Nil(t, err)
+	stacks, err := adventofcode2022.ParseStacks(lines[:9])
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Join(lines[:9], "\n"), stacks.String())
+
+	// 11 stacks, multi digit numbers
+	drawing := []string{
+		"                                        [K]",
+		"[A] [B]                             [I] [J]",
+		" 1   2   3   4   5   6   7   8   9  10  11 ",
+	}
+	stacks, err = adventofcode2022.ParseStacks(drawing)
+	assert.Nil(t, err)
+	assert.Equal(t, 11, len(stacks))
+	assert.Equal(t, "ABIK", stacks.Tops())
+	assert.Equal(t, 2, stacks[10].Len())
+	assert.Equal(t, strings.Join(drawing, "\n"), stacks.String())
+
+	_, err = adventofcode2022.ParseStacks([]string{"[A] B", " 1  2"})
+	assert.NotNil(t, err)
+	_, err = adventofcode2022.ParseStacks([]string{"[A]", " 2 "})
+	assert.NotNil(t, err)
+}
+
+func TestCrateReplay(t *testing.T) {
+	stacks, moves, err := adventofcode2022.ToStacksAndMoves(d5Example)
+	assert.Nil(t, err)
+
+	r := adventofcode2022.NewCrateReplay(stacks, moves, adventofcode2022.BatchCrane{Batch: 2})
+	assert.Nil(t, r.Seek(2))
+	// crates D N Z are moved to stack 3 in batches [D N], [Z]
+	assert.Equal(t, "        [Z]\n        [D]\n    [C] [N]\n    [M] [P]\n 1   2   3 ", r.Stacks.String())
+
+	assert.True(t, r.Undo())
+	assert.Equal(t, "[D]\n[N] [C]\n[Z] [M] [P]\n 1   2   3 ", r.Stacks.String())
+	assert.Nil(t, r.Seek(0))
+	assert.False(t, r.Undo())
+	assert.Equal(t, "    [D]\n[N] [C]\n[Z] [M] [P]\n 1   2   3 ", r.Stacks.String())
+
+	r = adventofcode2022.NewCrateReplay(stacks, moves, adventofcode2022.CrateMover9000)
+	for !r.Done() {
+		_, err := r.Next()
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, "CMZ", r.Stacks.Tops())
+	// original stacks aren't modified by replay
+	assert.Equal(t, "NDP", stacks.Tops())
+
+	bad := append(adventofcode2022.Moves{}, moves...)
+	m, err := adventofcode2022.ParseMove("move 5 from 3 to 1")
+	assert.Nil(t, err)
+	r = adventofcode2022.NewCrateReplay(stacks, append(bad, m), adventofcode2022.CrateMover9001)
+	assert.NotNil(t, r.Seek(len(moves)+1))
+	assert.Equal(t, len(moves), r.Step)
+}
+
+func TestCraneApplyErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		move string
+	}{
+		{"missing source stack", "move 1 from 4 to 1"},
+		{"missing destination stack", "move 1 from 1 to 4"},
+		{"not enough crates", "move 4 from 2 to 1"},
+		{"zero crates", "move 0 from 1 to 2"},
+		{"zero crates on the same stack", "move 0 from 1 to 1"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			stacks, _, err := adventofcode2022.ToStacksAndMoves(d5Example)
+			assert.Nil(t, err)
+			m, err := adventofcode2022.ParseMove(tc.move)
+			assert.Nil(t, err)
+			assert.NotNil(t, adventofcode2022.CrateMover9001.Apply(stacks, m))
+			assert.Equal(t, "NDP", stacks.Tops())
+			_, err = adventofcode2022.InitialStacks(stacks, adventofcode2022.Moves{m}, adventofcode2022.CrateMover9001)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestInitialStacks(t *testing.T) {
+	stacks, moves, err := adventofcode2022.ToStacksAndMoves(&adventofcode2022.FileToStringsInputReader{Path: "../adventofcode2022/day5.data"})
+	assert.Nil(t, err)
+
+	for _, crane := range []adventofcode2022.Crane{
+		adventofcode2022.CrateMover9000,
+		adventofcode2022.CrateMover9001,
+		adventofcode2022.BatchCrane{Batch: 3},
+	} {
+		r := adventofcode2022.NewCrateReplay(stacks, moves, crane)
+		assert.Nil(t, r.Seek(len(moves)))
+		initial, err := adventofcode2022.InitialStacks(r.Stacks, moves, crane)
+		assert.Nil(t, err)
+		assert.Equal(t, stacks.String(), initial.String())
+
+		initial, err = adventofcode2022.InitialStacksForTops("ADVENTOFC", moves, crane, "XYZ")
+		assert.Nil(t, err)
+		buf := bytes.Buffer{}
+		assert.Nil(t, adventofcode2022.WritePuzzleInput(&buf, initial, moves))
+		generated := linesReader(strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"))
+		s, m, err := adventofcode2022.ToStacksAndMoves(generated)
+		assert.Nil(t, err)
+		r = adventofcode2022.NewCrateReplay(s, m, crane)
+		assert.Nil(t, r.Seek(len(m)))
+		assert.Equal(t, "ADVENTOFC", r.Stacks.Tops())
+	}
+
+	// tops are numbered by letters, not by bytes
+	d5Stacks, d5Moves, err := adventofcode2022.ToStacksAndMoves(d5Example)
+	assert.Nil(t, err)
+	initial, err := adventofcode2022.InitialStacksForTops("ÄBÇ", d5Moves, adventofcode2022.CrateMover9000, "Ø")
+	assert.Nil(t, err)
+	assert.Equal(t, len(d5Stacks), len(initial))
+	r := adventofcode2022.NewCrateReplay(initial, d5Moves, adventofcode2022.CrateMover9000)
+	assert.Nil(t, r.Seek(len(d5Moves)))
+	assert.Equal(t, "ÄBÇ", r.Stacks.Tops())
+
+	_, err = adventofcode2022.InitialStacks(stacks, moves, adventofcode2022.CrateMover9000)
+	assert.NotNil(t, err)
+}
@@ -1,56 +1,170 @@
 package adventofcode2022
 
-import "fmt"
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
 
+// Marker is a position in a stream where the last Size bytes are pairwise distinct,
+// Position is the number of bytes processed including the last byte of the window
+type Marker struct {
+	Size     int
+	Position int
+}
 
+type markerWindow struct {
+	size   int
+	counts [256]int
+	// number of byte values which occur in the window more than once
+	dups int
+}
 
-func Task6_1(ir InputReader, cnvrtInpt func(InputReader) (string, error)) (string, error) {
-	data, err := cnvrtInpt(ir)
-	if err != nil {
-		return "", err
-	}
+// MarkerDetector is a writer which looks for markers of several sizes at once,
+// every byte is processed in O(1) for every size, so input can be of any length
+type MarkerDetector struct {
+	// OnMarker is called for every marker found, markers of different sizes ending at the same position
+	// are reported in the order sizes were passed to NewMarkerDetector
+	OnMarker func(m Marker)
 
-	idx := getIdxFirstUniqueSubsEnds(data, 4)
+	pos     int
+	last    []byte
+	windows []*markerWindow
+}
 
-	if idx == -1 {
-		return "not found", nil
-	} else {
-		return fmt.Sprintf("Result: %v", idx), nil
+// NewMarkerDetector creates a detector for markers of given sizes, sizes must be distinct
+func NewMarkerDetector(sizes ...int) (*MarkerDetector, error) {
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("expected at least one window size")
 	}
+	maxSize := 0
+	windows := []*markerWindow{}
+	seen := map[int]bool{}
+	for _, s := range sizes {
+		if s < 1 || s > 256 {
+			return nil, fmt.Errorf("expected window size from 1 to 256, got: %v", s)
+		}
+		if seen[s] {
+			return nil, fmt.Errorf("duplicate window size: %v", s)
+		}
+		seen[s] = true
+		maxSize = Max(maxSize, s)
+		windows = append(windows, &markerWindow{size: s})
+	}
+	return &MarkerDetector{
+		last:    make([]byte, maxSize),
+		windows: windows,
+	}, nil
 }
 
-func Task6_2(ir InputReader, cnvrtInpt func(InputReader) (string, error)) (string, error) {
-	data, err := cnvrtInpt(ir)
-	if err != nil {
-		return "", err
+// Position returns number of processed bytes
+func (d *MarkerDetector) Position() int {
+	return d.pos
+}
+
+func (d *MarkerDetector) Write(p []byte) (int, error) {
+	for _, b := range p {
+		d.WriteByte(b)
 	}
+	return len(p), nil
+}
 
-	idx := getIdxFirstUniqueSubsEnds(data, 14)
+func (d *MarkerDetector) WriteByte(b byte) error {
+	// last is a ring buffer of the latest bytes, it's enough to evict bytes from the biggest window
+	slot := d.pos % len(d.last)
+	evicted := d.last[slot]
+	d.last[slot] = b
+	d.pos++
+	for _, w := range d.windows {
+		w.counts[b]++
+		if w.counts[b] == 2 {
+			w.dups++
+		}
+		if d.pos > w.size {
+			old := evicted
+			if w.size < len(d.last) {
+				old = d.last[(d.pos-1-w.size)%len(d.last)]
+			}
+			w.counts[old]--
+			if w.counts[old] == 1 {
+				w.dups--
+			}
+		}
+		if d.pos >= w.size && w.dups == 0 && d.OnMarker != nil {
+			d.OnMarker(Marker{Size: w.size, Position: d.pos})
+		}
+	}
+	return nil
+}
 
-	if idx == -1 {
-		return "not found", nil
-	} else {
-		return fmt.Sprintf("Result: %v", idx), nil
+// FindMarkers returns all markers of given sizes in the stream
+func FindMarkers(r io.Reader, sizes ...int) ([]Marker, error) {
+	d, err := NewMarkerDetector(sizes...)
+	if err != nil {
+		return nil, err
 	}
+	res := []Marker{}
+	d.OnMarker = func(m Marker) {
+		res = append(res, m)
+	}
+	if _, err := io.Copy(d, r); err != nil {
+		return nil, err
+	}
+	return res, nil
 }
 
-func getIdxFirstUniqueSubsEnds(str string, substrlen int) int {
-	substrendsIdx := -1
-	for i := 0; i < len(str)-substrlen; i++ {
-		checker := 0
-		found := true
-		for j := i; j < i+substrlen; j++ {
-			bitIdx := str[j] - 'a'
-			if (checker & (1 << bitIdx)) > 0 {
-				found = false
-				break
-			}
-			checker |= (1 << bitIdx)
+// FirstMarkers returns position of the first marker for every size, the stream is read only until
+// all of them are found. Sizes without markers are absent in the result
+func FirstMarkers(r io.Reader, sizes ...int) (map[int]int, error) {
+	d, err := NewMarkerDetector(sizes...)
+	if err != nil {
+		return nil, err
+	}
+	res := map[int]int{}
+	d.OnMarker = func(m Marker) {
+		if _, ok := res[m.Size]; !ok {
+			res[m.Size] = m.Position
 		}
-		if found {
-			substrendsIdx = i + substrlen
+	}
+	br := bufio.NewReader(r)
+	for len(res) < len(d.windows) {
+		b, err := br.ReadByte()
+		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return nil, err
+		}
+		d.WriteByte(b)
+	}
+	return res, nil
+}
+
+func firstMarker(data string, size int) (string, error) {
+	found, err := FirstMarkers(strings.NewReader(data), size)
+	if err != nil {
+		return "", err
+	}
+	idx, ok := found[size]
+	if !ok {
+		return "not found", nil
+	}
+	return fmt.Sprintf("Result: %v", idx), nil
+}
+
+func Task6_1(ir InputReader, cnvrtInpt func(InputReader) (string, error)) (string, error) {
+	data, err := cnvrtInpt(ir)
+	if err != nil {
+		return "", err
+	}
+	return firstMarker(data, 4)
+}
+
+func Task6_2(ir InputReader, cnvrtInpt func(InputReader) (string, error)) (string, error) {
+	data, err := cnvrtInpt(ir)
+	if err != nil {
+		return "", err
 	}
-	return substrendsIdx
+	return firstMarker(data, 14)
 }
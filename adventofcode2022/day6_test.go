@@ -0,0 +1,65 @@
+package adventofcode2022_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirstMarkers(t *testing.T) {
+	cases := []struct {
+		data   string
+		packet int
+		msg    int
+	}{
+		{"mjqjpqmgbljsphdztnvjfqwrcgsmlb", 7, 19},
+		{"bvwbjplbgvbhsrlpgdmjqwftvncz", 5, 23},
+		{"nppdvjthqldpwncqszvftbrmjlhg", 6, 23},
+		{"nznrnfrfntjfmvfwmzdfjlvtqnbhcprsg", 10, 29},
+		{"zcfzfwzzqfrljwzlrfnpqdbhtmscgvjw", 11, 26},
+	}
+	for _, c := range cases {
+		found, err := adventofcode2022.FirstMarkers(strings.NewReader(c.data), 4, 14)
+		assert.Nil(t, err)
+		assert.Equal(t, map[int]int{4: c.packet, 14: c.msg}, found, c.data)
+	}
+
+	found, err := adventofcode2022.FirstMarkers(strings.NewReader("aabb"), 2, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]int{2: 3}, found)
+
+	_, err = adventofcode2022.FirstMarkers(strings.NewReader("abc"), 0)
+	assert.NotNil(t, err)
+	_, err = adventofcode2022.FirstMarkers(strings.NewReader("abcd"), 4, 2, 4)
+	assert.NotNil(t, err)
+	_, err = adventofcode2022.NewMarkerDetector(3, 3)
+	assert.NotNil(t, err)
+}
+
+func TestFindMarkers(t *testing.T) {
+	markers, err := adventofcode2022.FindMarkers(strings.NewReader("abcabba"), 3, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []adventofcode2022.Marker{
+		{Size: 2, Position: 2},
+		{Size: 3, Position: 3},
+		{Size: 2, Position: 3},
+		{Size: 3, Position: 4},
+		{Size: 2, Position: 4},
+		{Size: 3, Position: 5},
+		{Size: 2, Position: 5},
+		{Size: 2, Position: 7},
+	}, markers)
+
+	// arbitrary bytes, the only window of 256 distinct bytes is in the middle
+	data := []byte{0, 0}
+	for i := 0; i < 256; i++ {
+		data = append(data, byte(i))
+	}
+	data = append(data, 255)
+	markers, err = adventofcode2022.FindMarkers(bytes.NewReader(data), 256)
+	assert.Nil(t, err)
+	assert.Equal(t, []adventofcode2022.Marker{{Size: 256, Position: 258}}, markers)
+}
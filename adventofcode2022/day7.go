@@ -2,9 +2,6 @@ package adventofcode2022
 
 import (
 	"fmt"
-	"regexp"
-	"sort"
-	"strconv"
 	"strings"
 )
 
@@ -14,6 +11,10 @@ const (
 	NotSupported CmdName = iota
 	LS
 	CD
+	MKDIR
+	RM
+	DU
+	FIND
 )
 
 func GetCmdName(cmd string) CmdName {
@@ -22,6 +23,14 @@ func GetCmdName(cmd string) CmdName {
 		return LS
 	case "cd":
 		return CD
+	case "mkdir":
+		return MKDIR
+	case "rm":
+		return RM
+	case "du":
+		return DU
+	case "find":
+		return FIND
 	default:
 		return NotSupported
 	}
@@ -29,6 +38,7 @@ func GetCmdName(cmd string) CmdName {
 
 type Command struct {
 	CMD    CmdName
+	Name   string
 	Args   []string
 	Output []string
 }
@@ -48,24 +58,19 @@ func ToCmdQueue(ir InputReader) (CommandQueue, error) {
 		if err != nil {
 			return nil, err
 		}
-		switch command.CMD {
-		case LS:
-			parsedOut, parsedLinesCnt := parseLSOutput(i+1, content)
-			i += parsedLinesCnt
-			command.Output = parsedOut
-			cmdQueue = append(cmdQueue, command)
-		case CD:
-			cmdQueue = append(cmdQueue, command)
-		case NotSupported:
-			return nil, fmt.Errorf("found unsuppoerted command: %v", command.CMD)
-		default:
-			return nil, fmt.Errorf("unexpected behaviour")
+		if command.CMD == NotSupported {
+			return nil, fmt.Errorf("found unsupported command: %v", command.Name)
 		}
+		// every command may have output, it's used only by ls, output of other commands is ignored on replay
+		parsedOut, parsedLinesCnt := parseCmdOutput(i+1, content)
+		i += parsedLinesCnt
+		command.Output = parsedOut
+		cmdQueue = append(cmdQueue, command)
 	}
 	return cmdQueue, nil
 }
 
-func parseLSOutput(lineN int, lines []string) ([]string, int) {
+func parseCmdOutput(lineN int, lines []string) ([]string, int) {
 	res := []string{}
 	parsedCnt := 0
 	for i := lineN; i < len(lines); i++ {
@@ -80,18 +85,11 @@ func parseLSOutput(lineN int, lines []string) ([]string, int) {
 }
 
 func parseCmd(line string) (*Command, error) {
-	cmdRe := regexp.MustCompile(`(\$) (\w+)\s?(.+)?`)
-	parsed := cmdRe.FindStringSubmatch(line)
-	if parsed[1] != "$" {
-		return nil, fmt.Errorf("%v not a command", line)
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "$" {
+		return nil, fmt.Errorf("expected format: [$ <cmd> <args>], got: %v", line)
 	}
-	cmd := GetCmdName(parsed[2])
-	args := []string{}
-	if len(parsed) == 4 {
-		parsedArgs := strings.Split(parsed[3], "\\s")
-		args = append(args, parsedArgs...)
-	}
-	return &Command{CMD: cmd, Args: args}, nil
+	return &Command{CMD: GetCmdName(fields[1]), Name: fields[1], Args: fields[2:]}, nil
 }
 
 type NodeType int
@@ -109,10 +107,12 @@ type Tree struct {
 	Children map[string]*Tree
 }
 
+// populateDirSizes recalculates sizes of all directories in the tree
 func (t *Tree) populateDirSizes() int {
 	if t.Type == File {
 		return t.Size
 	}
+	t.Size = 0
 	for _, ch := range t.Children {
 		size := ch.populateDirSizes()
 		t.Size += size
@@ -120,91 +120,13 @@ func (t *Tree) populateDirSizes() int {
 	return t.Size
 }
 
-const LS_OUTPUT_DIR_PREFIX = "dir"
-
-func parseLsOutput(output []string, node *Tree) error {
-	for _, item := range output {
-		if err := parseLsOutputLine(item, node); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// Expected ls output either
-// dir <dir name>
-// <file size> <file name>
-func parseLsOutputLine(item string, node *Tree) error {
-	splitted := strings.Split(item, " ")
-	if len(splitted) != 2 {
-		return fmt.Errorf("got: %v, wanted either: [dir <dirname>] or [<size> <filename>]", item)
-	}
-	if splitted[0] == LS_OUTPUT_DIR_PREFIX {
-		return nil
-	}
-	size, err := strconv.Atoi(splitted[0])
-	if err != nil {
-		return err
-	}
-	file := splitted[1]
-	node.Children[file] = &Tree{
-		Type:     File,
-		Size:     size,
-		Name:     file,
-		Parent:   node,
-		Children: map[string]*Tree{},
-	}
-	return nil
-}
-
-const (
-	ROOT_DIR = "/"
-	GO_UP    = ".."
-)
-
 func buildDirTreeByCmdOuque(cq CommandQueue) (*Tree, error) {
-	var root *Tree = &Tree{
-		Type:     Dir,
-		Name:     ROOT_DIR,
-		Children: map[string]*Tree{},
-		Parent:   nil,
-	}
-	var currentNode *Tree = nil
-	for _, cmd := range cq {
-		switch cmd.CMD {
-		case LS:
-			if err := parseLsOutput(cmd.Output, currentNode); err != nil {
-				return nil, err
-			}
-		case CD:
-			switch cmd.Args[0] {
-			case ROOT_DIR:
-				currentNode = root
-			case GO_UP:
-				// skip if we already in root dir
-				if currentNode.Parent == nil {
-					continue
-				}
-				currentNode = currentNode.Parent
-			default:
-				ch, ok := currentNode.Children[cmd.Args[0]]
-				if !ok {
-					ch = &Tree{
-						Name:     cmd.Args[0],
-						Type:     Dir,
-						Parent:   currentNode,
-						Children: map[string]*Tree{},
-					}
-					currentNode.Children[cmd.Args[0]] = ch
-				}
-				currentNode = ch
-			}
-		default:
-			return nil, fmt.Errorf("unexpected cmd: %v", cmd)
-		}
+	fs := NewVFS()
+	if err := fs.Replay(cq); err != nil {
+		return nil, err
 	}
-	root.populateDirSizes()
-	return root, nil
+	fs.Root.populateDirSizes()
+	return fs.Root, nil
 }
 
 func (t *Tree) sumSizesByCondition(cond func(*Tree) bool) int {
@@ -221,21 +143,7 @@ func (t *Tree) sumSizesByCondition(cond func(*Tree) bool) int {
 	return sum
 }
 
-func (t *Tree) findAllByCondition(cond func(*Tree) bool) []*Tree {
-	if t.Type == File {
-		return []*Tree{}
-	}
-	found := []*Tree{}
-	for _, ch := range t.Children {
-		if cond(ch) {
-			found = append(found, ch)
-		}
-		found = append(found, ch.findAllByCondition(cond)...)
-	}
-	return found
-}
-
-func Task7_1(ir InputReader, cnvrInpt func(InputReader) (CommandQueue, error)) (string, error) {
+func Task7_1(ir InputReader, cnvrInpt func(InputReader) (CommandQueue, error), debug bool) (string, error) {
 	cmdQueue, err := cnvrInpt(ir)
 	if err != nil {
 		return "", err
@@ -244,6 +152,9 @@ func Task7_1(ir InputReader, cnvrInpt func(InputReader) (CommandQueue, error)) (
 	if err != nil {
 		return "", err
 	}
+	if debug {
+		debugD7Tree(root)
+	}
 	sum := root.sumSizesByCondition(func(t *Tree) bool { return t.Type == Dir && t.Size <= 100000 })
 	return fmt.Sprintf("Result: %v", sum), nil
 }
@@ -253,7 +164,30 @@ const (
 	MIN_EXPECTED_FREE_SPACE = 30000000
 )
 
-func Task7_2(ir InputReader, cnvrInpt func(InputReader) (CommandQueue, error)) (string, error) {
+// SmallestDirToFree returns the smallest directory which has to be deleted to get required unused space
+// on a disk of total size, nil is returned if there's already enough unused space.
+// Directory sizes have to be populated beforehand
+func (t *Tree) SmallestDirToFree(total int, required int) (*Tree, error) {
+	needToCleanUp := required - (total - t.Size)
+	if needToCleanUp <= 0 {
+		return nil, nil
+	}
+	if t.Size > total {
+		return nil, fmt.Errorf("used space %v exceeds disk size %v", t.Size, total)
+	}
+	if required > total {
+		return nil, fmt.Errorf("required space %v exceeds disk size %v", required, total)
+	}
+	var res *Tree
+	for _, d := range t.Find(func(n *Tree) bool { return n.Type == Dir && n.Size >= needToCleanUp }) {
+		if res == nil || d.Size < res.Size {
+			res = d
+		}
+	}
+	return res, nil
+}
+
+func Task7_2(ir InputReader, cnvrInpt func(InputReader) (CommandQueue, error), debug bool) (string, error) {
 	cmdQueue, err := cnvrInpt(ir)
 	if err != nil {
 		return "", err
@@ -262,12 +196,15 @@ func Task7_2(ir InputReader, cnvrInpt func(InputReader) (CommandQueue, error)) (
 	if err != nil {
 		return "", err
 	}
-	needToCleanUp := MIN_EXPECTED_FREE_SPACE - (MAX_SIZE - root.Size)
-	found := root.findAllByCondition(func(t *Tree) bool { return t.Type == Dir && t.Size >= needToCleanUp })
-	sizes := []int{}
-	for _, f := range found {
-		sizes = append(sizes, f.Size)
+	if debug {
+		debugD7Tree(root)
+	}
+	dir, err := root.SmallestDirToFree(MAX_SIZE, MIN_EXPECTED_FREE_SPACE)
+	if err != nil {
+		return "", err
+	}
+	if dir == nil {
+		return "Result: 0", nil
 	}
-	sort.Ints(sizes)
-	return fmt.Sprintf("Result: %v", sizes[0]), nil
+	return fmt.Sprintf("Result: %v", dir.Size), nil
 }
@@ -0,0 +1,102 @@
+package adventofcode2022
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// WriteTree prints the tree like unix tree utility does, with sizes of files and directories,
+// directory sizes have to be populated beforehand
+func (t *Tree) WriteTree(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%v (%v)\n", t.Name, t.Size); err != nil {
+		return err
+	}
+	return t.writeTreeChildren(w, "")
+}
+
+func (t *Tree) writeTreeChildren(w io.Writer, prefix string) error {
+	children := t.sortedChildren()
+	for i, ch := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		name := ch.Name
+		if ch.Type == Dir {
+			name += "/"
+		}
+		if _, err := fmt.Fprintf(w, "%v%v%v (%v)\n", prefix, branch, name, ch.Size); err != nil {
+			return err
+		}
+		if err := ch.writeTreeChildren(w, prefix+indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDu prints [size<TAB>path] lines in post-order like du does, files are printed only if all is set,
+// directory sizes have to be populated beforehand
+func (t *Tree) WriteDu(w io.Writer, all bool) error {
+	return t.writeDu(w, all, strconv.Itoa)
+}
+
+func (t *Tree) writeDu(w io.Writer, all bool, format func(int) string) error {
+	for _, ch := range t.sortedChildren() {
+		if ch.Type == File && !all {
+			continue
+		}
+		if err := ch.writeDu(w, all, format); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%v\t%v\n", format(t.Size), t.Path())
+	return err
+}
+
+type treeJSON struct {
+	Name     string     `json:"name"`
+	Type     string     `json:"type"`
+	Size     int        `json:"size"`
+	Children []treeJSON `json:"children,omitempty"`
+}
+
+func (t *Tree) toJSON() treeJSON {
+	res := treeJSON{Name: t.Name, Type: "file", Size: t.Size}
+	if t.Type == Dir {
+		res.Type = "dir"
+		for _, ch := range t.sortedChildren() {
+			res.Children = append(res.Children, ch.toJSON())
+		}
+	}
+	return res
+}
+
+// WriteJSON writes the tree as nested objects with name, type (dir or file), size and children
+func (t *Tree) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t.toJSON())
+}
+
+func debugD7Tree(root *Tree) {
+	writers := map[string]func(io.Writer) error{
+		"debug_d7_tree.debug": root.WriteTree,
+		"debug_d7_du.debug":   func(w io.Writer) error { return root.WriteDu(w, true) },
+		"debug_d7_tree.json":  root.WriteJSON,
+	}
+	for name, write := range writers {
+		f, err := os.Create(name)
+		if err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+			continue
+		}
+		if err := write(f); err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+		}
+		f.Close()
+	}
+}
@@ -0,0 +1,135 @@
+package adventofcode2022
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Shell is an interactive shell over VFS, it reads commands line by line from in
+type Shell struct {
+	FS *VFS
+	// disk parameters used by free command when they aren't passed explicitly
+	DiskSize      int
+	RequiredSpace int
+
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+func NewShell(fs *VFS, in io.Reader, out io.Writer) *Shell {
+	return &Shell{
+		FS:            fs,
+		DiskSize:      MAX_SIZE,
+		RequiredSpace: MIN_EXPECTED_FREE_SPACE,
+		in:            bufio.NewScanner(in),
+		out:           out,
+	}
+}
+
+const shellHelp = `commands:
+  cd <dir>                                  change directory
+  ls [-l] [path]                            list directory, -l prints type and size of every entry
+  pwd                                       print current directory
+  du [-a] [-h] [path]                       print directory sizes, -a includes files, -h human readable sizes
+  find [path] [-type d|f] [-size [+|-]N] [-name pattern]
+                                            find files and directories, +N greater than N bytes, -N less than N
+  tree [path]                               print directory tree
+  mkdir [-p] <dir>...                       create directories
+  rm [-r] <path>...                         remove files or directories
+  free [disk size] [required space]         find the smallest directory to delete to get required space
+  h, help                                   print this help
+  q, quit, exit                             exit
+`
+
+// Run reads and executes commands until quit or end of input
+func (s *Shell) Run() error {
+	fmt.Fprintln(s.out, "type 'help' for commands")
+	for {
+		fmt.Fprintf(s.out, "%v$ ", s.FS.Cwd.Path())
+		if !s.in.Scan() {
+			fmt.Fprintln(s.out)
+			return s.in.Err()
+		}
+		fields := strings.Fields(s.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		quit, err := s.exec(fields[0], fields[1:])
+		if err != nil {
+			fmt.Fprintf(s.out, "error: %v\n", err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+func (s *Shell) exec(cmd string, args []string) (bool, error) {
+	switch cmd {
+	case "pwd":
+		fmt.Fprintln(s.out, s.FS.Cwd.Path())
+		return false, nil
+	case "tree":
+		return false, s.tree(args)
+	case "free":
+		return false, s.free(args)
+	case "h", "help":
+		fmt.Fprint(s.out, shellHelp)
+		return false, nil
+	case "q", "quit", "exit":
+		return true, nil
+	}
+
+	name := GetCmdName(cmd)
+	if name == NotSupported {
+		return false, fmt.Errorf("unknown command: [%v], type 'help' for commands", cmd)
+	}
+	return false, s.FS.Exec(&Command{CMD: name, Name: cmd, Args: args}, s.out)
+}
+
+func (s *Shell) tree(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("expected: tree [path]")
+	}
+	node := s.FS.Cwd
+	if len(args) == 1 {
+		var err error
+		if node, err = s.FS.Resolve(args[0]); err != nil {
+			return err
+		}
+	}
+	s.FS.Root.populateDirSizes()
+	return node.WriteTree(s.out)
+}
+
+func (s *Shell) free(args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf("expected: free [disk size] [required space]")
+	}
+	params := []int{s.DiskSize, s.RequiredSpace}
+	for i, a := range args {
+		v, err := strconv.Atoi(a)
+		if err != nil || v < 0 {
+			return fmt.Errorf("expected non negative number, got: [%v]", a)
+		}
+		params[i] = v
+	}
+	total, required := params[0], params[1]
+
+	root := s.FS.Root
+	root.populateDirSizes()
+	dir, err := root.SmallestDirToFree(total, required)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "disk: %v, used: %v, unused: %v, required: %v\n", total, root.Size, total-root.Size, required)
+	if dir == nil {
+		fmt.Fprintln(s.out, "there is enough unused space, nothing to delete")
+		return nil
+	}
+	fmt.Fprintf(s.out, "delete %v to free %v\n", dir.Path(), dir.Size)
+	return nil
+}
@@ -0,0 +1,162 @@
+package adventofcode2022_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+var d7Example = linesReader(strings.Split(`$ cd /
+$ ls
+dir a
+14848514 b.txt
+8504156 c.dat
+dir d
+$ cd a
+$ ls
+dir e
+29116 f
+2557 g
+62596 h.lst
+$ cd e
+$ ls
+584 i
+$ cd ..
+$ cd ..
+$ cd d
+$ ls
+4060174 j
+8033020 d.log
+5626152 d.ext
+7214296 k`, "\n"))
+
+func TestTask7(t *testing.T) {
+	res, err := adventofcode2022.Task7_1(d7Example, adventofcode2022.ToCmdQueue, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "Result: 95437", res)
+
+	res, err = adventofcode2022.Task7_2(d7Example, adventofcode2022.ToCmdQueue, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "Result: 24933642", res)
+}
+
+func replay(t *testing.T, lines ...string) (*adventofcode2022.VFS, string) {
+	cq, err := adventofcode2022.ToCmdQueue(linesReader(lines))
+	assert.Nil(t, err)
+	fs := adventofcode2022.NewVFS()
+	out := bytes.Buffer{}
+	for _, cmd := range cq {
+		assert.Nil(t, fs.Exec(cmd, &out))
+	}
+	return fs, out.String()
+}
+
+func TestVFSEmptyDirs(t *testing.T) {
+	fs, _ := replay(t, "$ ls", "dir empty", "10 f", "$ mkdir -p x/y", "$ cd x/y")
+	assert.Equal(t, "/x/y", fs.Cwd.Path())
+
+	out := bytes.Buffer{}
+	assert.Nil(t, fs.Root.WriteJSON(&out))
+	assert.Contains(t, out.String(), `"name": "empty"`)
+
+	out.Reset()
+	assert.Nil(t, fs.Exec(&adventofcode2022.Command{CMD: adventofcode2022.DU, Name: "du", Args: []string{"-a", "/"}}, &out))
+	assert.Equal(t, "0\t/empty\n10\t/f\n0\t/x/y\n0\t/x\n10\t/\n", out.String())
+
+	out.Reset()
+	assert.Nil(t, fs.Root.WriteTree(&out))
+	assert.Equal(t, "/ (10)\n├── empty/ (0)\n├── f (10)\n└── x/ (0)\n    └── y/ (0)\n", out.String())
+}
+
+func TestVFSCommands(t *testing.T) {
+	cq, err := adventofcode2022.ToCmdQueue(d7Example)
+	assert.Nil(t, err)
+	fs := adventofcode2022.NewVFS()
+	assert.Nil(t, fs.Replay(cq))
+
+	cq, err = adventofcode2022.ToCmdQueue(linesReader{
+		"$ cd /",
+		"$ rm -r a",
+		"$ rm d/j",
+		"$ find -type f -size +8000000",
+		"$ du",
+	})
+	assert.Nil(t, err)
+	out := bytes.Buffer{}
+	for _, cmd := range cq {
+		assert.Nil(t, fs.Exec(cmd, &out))
+	}
+	assert.Equal(t, "/b.txt\n/c.dat\n/d/d.log\n"+"20873468\t/d\n44226138\t/\n", out.String())
+
+	fs, _ = replay(t, "$ mkdir a")
+	for _, cmd := range []string{"$ rm a", "$ cd b", "$ mkdir a", "$ find -size x", "$ rm /"} {
+		cq, err := adventofcode2022.ToCmdQueue(linesReader{cmd})
+		assert.Nil(t, err)
+		assert.NotNil(t, fs.Exec(cq[0], &bytes.Buffer{}), cmd)
+	}
+
+	// transcripts can cd into directories which were never listed, only interactive cd is strict
+	cq, err = adventofcode2022.ToCmdQueue(linesReader{"$ cd a", "$ cd b", "$ ls", "10 f", "$ cd /"})
+	assert.Nil(t, err)
+	fs = adventofcode2022.NewVFS()
+	assert.Nil(t, fs.Replay(cq))
+	dir, err := fs.Resolve("/a/b/f")
+	assert.Nil(t, err)
+	assert.Equal(t, "/a/b/f", dir.Path())
+	cq, err = adventofcode2022.ToCmdQueue(linesReader{"$ cd c"})
+	assert.Nil(t, err)
+	assert.NotNil(t, fs.Exec(cq[0], &bytes.Buffer{}))
+
+	_, err = adventofcode2022.ToCmdQueue(linesReader{"$ cat a"})
+	assert.NotNil(t, err)
+}
+
+func TestShell(t *testing.T) {
+	cq, err := adventofcode2022.ToCmdQueue(d7Example)
+	assert.Nil(t, err)
+	fs := adventofcode2022.NewVFS()
+	assert.Nil(t, fs.Replay(cq))
+	fs.Cwd = fs.Root
+
+	in := strings.NewReader("cd /a\npwd\nls -l\nfind / -name *.*\ndu -h /\nfree\nfree 100000000\nunknown\nquit\n")
+	out := bytes.Buffer{}
+	assert.Nil(t, adventofcode2022.NewShell(fs, in, &out).Run())
+
+	expected := `type 'help' for commands
+/$ /a$ /a
+/a$ d        584 e
+-      29116 f
+-       2557 g
+-      62596 h.lst
+/a$ /a/h.lst
+/b.txt
+/c.dat
+/d/d.ext
+/d/d.log
+/a$ 584	/a/e
+93K	/a
+24M	/d
+47M	/
+/a$ disk: 70000000, used: 48381165, unused: 21618835, required: 30000000
+delete /d to free 24933642
+/a$ disk: 100000000, used: 48381165, unused: 51618835, required: 30000000
+there is enough unused space, nothing to delete
+/a$ error: unknown command: [unknown], type 'help' for commands
+/a$ `
+	assert.Equal(t, expected, out.String())
+}
+
+func TestHumanSize(t *testing.T) {
+	assert.Equal(t, "584", adventofcode2022.HumanSize(584))
+	assert.Equal(t, "1.0K", adventofcode2022.HumanSize(1024))
+	assert.Equal(t, "1.1K", adventofcode2022.HumanSize(1025))
+	assert.Equal(t, "93K", adventofcode2022.HumanSize(94853))
+	assert.Equal(t, "47M", adventofcode2022.HumanSize(48381165))
+	// unit is chosen after rounding
+	assert.Equal(t, "10K", adventofcode2022.HumanSize(10*1024-1))
+	assert.Equal(t, "1.0M", adventofcode2022.HumanSize(1024*1024-1))
+	assert.Equal(t, "1.0G", adventofcode2022.HumanSize(1024*1024*1024-1))
+}
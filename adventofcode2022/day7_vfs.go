@@ -0,0 +1,518 @@
+package adventofcode2022
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VFS is an in-memory filesystem which can replay terminal transcripts from day 7,
+// directory sizes are recalculated lazily by commands which need them
+type VFS struct {
+	Root *Tree
+	Cwd  *Tree
+
+	// transcripts can cd into directories which were never listed, during replay they are created
+	replaying bool
+}
+
+func NewVFS() *VFS {
+	root := newDir(ROOT_DIR, nil)
+	return &VFS{Root: root, Cwd: root}
+}
+
+const (
+	ROOT_DIR = "/"
+	GO_UP    = ".."
+)
+
+func newDir(name string, parent *Tree) *Tree {
+	return &Tree{Type: Dir, Name: name, Parent: parent, Children: map[string]*Tree{}}
+}
+
+// Path returns absolute path of the node
+func (t *Tree) Path() string {
+	if t.Parent == nil {
+		return ROOT_DIR
+	}
+	parent := t.Parent.Path()
+	if parent == ROOT_DIR {
+		return ROOT_DIR + t.Name
+	}
+	return parent + "/" + t.Name
+}
+
+// sortedChildren returns children ordered by name, map order isn't stable
+func (t *Tree) sortedChildren() []*Tree {
+	res := make([]*Tree, 0, len(t.Children))
+	for _, ch := range t.Children {
+		res = append(res, ch)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// walk visits nodes in pre-order, children are visited in name order
+func (t *Tree) walk(visit func(*Tree)) {
+	visit(t)
+	for _, ch := range t.sortedChildren() {
+		ch.walk(visit)
+	}
+}
+
+// Find returns all nodes of the tree (including t) matching every condition, in pre-order
+func (t *Tree) Find(conds ...func(*Tree) bool) []*Tree {
+	found := []*Tree{}
+	t.walk(func(n *Tree) {
+		for _, c := range conds {
+			if !c(n) {
+				return
+			}
+		}
+		found = append(found, n)
+	})
+	return found
+}
+
+// Resolve returns node by absolute or relative (to current directory) path
+func (fs *VFS) Resolve(path string) (*Tree, error) {
+	node := fs.Cwd
+	if strings.HasPrefix(path, ROOT_DIR) {
+		node = fs.Root
+	}
+	for _, part := range strings.Split(path, "/") {
+		switch part {
+		case "", ".":
+			continue
+		case GO_UP:
+			if node.Parent != nil {
+				node = node.Parent
+			}
+		default:
+			if node.Type != Dir {
+				return nil, fmt.Errorf("%v: not a directory", node.Path())
+			}
+			ch, ok := node.Children[part]
+			if !ok {
+				return nil, fmt.Errorf("%v: no such file or directory", path)
+			}
+			node = ch
+		}
+	}
+	return node, nil
+}
+
+// resolveParent splits path into existing parent directory and name of the last element
+func (fs *VFS) resolveParent(path string) (*Tree, string, error) {
+	path = strings.TrimSuffix(path, "/")
+	idx := strings.LastIndex(path, "/")
+	parentPath, name := ".", path
+	if idx >= 0 {
+		parentPath, name = path[:idx+1], path[idx+1:]
+	}
+	if name == "" || name == "." || name == GO_UP {
+		return nil, "", fmt.Errorf("%v: invalid name", path)
+	}
+	parent, err := fs.Resolve(parentPath)
+	if err != nil {
+		return nil, "", err
+	}
+	if parent.Type != Dir {
+		return nil, "", fmt.Errorf("%v: not a directory", parent.Path())
+	}
+	return parent, name, nil
+}
+
+// Replay executes commands one by one, output of commands is discarded
+func (fs *VFS) Replay(cq CommandQueue) error {
+	fs.replaying = true
+	defer func() { fs.replaying = false }()
+	for i, cmd := range cq {
+		if err := fs.Exec(cmd, io.Discard); err != nil {
+			return fmt.Errorf("command %v [%v]: %w", i+1, cmd, err)
+		}
+	}
+	return nil
+}
+
+func (c *Command) String() string {
+	return strings.Join(append([]string{"$", c.Name}, c.Args...), " ")
+}
+
+// Exec executes a single command and writes its output to out.
+// Recorded output of ls is used to populate the listed directory,
+// output recorded for other commands is ignored, it's generated from the tree instead
+func (fs *VFS) Exec(cmd *Command, out io.Writer) error {
+	switch cmd.CMD {
+	case CD:
+		return fs.cd(cmd.Args)
+	case LS:
+		return fs.ls(cmd.Args, cmd.Output, out)
+	case MKDIR:
+		return fs.mkdir(cmd.Args)
+	case RM:
+		return fs.rm(cmd.Args)
+	case DU:
+		return fs.du(cmd.Args, out)
+	case FIND:
+		return fs.find(cmd.Args, out)
+	default:
+		return fmt.Errorf("unsupported command: %v", cmd.Name)
+	}
+}
+
+// splitFlags separates leading arguments started with '-' from the rest
+func splitFlags(args []string) ([]string, []string) {
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") && len(args[i]) > 1 {
+		i++
+	}
+	return args[:i], args[i:]
+}
+
+// parseFlagLetters parses short flags like -a -h or combined -ah, allowed contains supported letters
+func parseFlagLetters(cmd string, flags []string, allowed string) (map[rune]bool, error) {
+	res := map[rune]bool{}
+	for _, f := range flags {
+		for _, c := range f[1:] {
+			if !strings.ContainsRune(allowed, c) {
+				return nil, fmt.Errorf("%v: unsupported flag: -%c", cmd, c)
+			}
+			res[c] = true
+		}
+	}
+	return res, nil
+}
+
+func (fs *VFS) cd(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected: cd <dir>")
+	}
+	dir, err := fs.Resolve(args[0])
+	if err != nil && fs.replaying {
+		if err := fs.mkdirAll(args[0]); err != nil {
+			return err
+		}
+		dir, err = fs.Resolve(args[0])
+	}
+	if err != nil {
+		return err
+	}
+	if dir.Type != Dir {
+		return fmt.Errorf("%v: not a directory", args[0])
+	}
+	fs.Cwd = dir
+	return nil
+}
+
+// ls [-l] [dir], prints entries in the same format as transcripts do,
+// with -l every entry is printed with type and size, directory sizes included
+func (fs *VFS) ls(args []string, recorded []string, out io.Writer) error {
+	flags, paths := splitFlags(args)
+	opts, err := parseFlagLetters("ls", flags, "l")
+	if err != nil {
+		return err
+	}
+	if len(paths) > 1 {
+		return fmt.Errorf("expected: ls [-l] [dir]")
+	}
+	dir := fs.Cwd
+	if len(paths) == 1 {
+		if dir, err = fs.Resolve(paths[0]); err != nil {
+			return err
+		}
+	}
+	for _, line := range recorded {
+		if dir.Type != Dir {
+			return fmt.Errorf("%v: not a directory", dir.Path())
+		}
+		if err := dir.addLsEntry(line); err != nil {
+			return err
+		}
+	}
+
+	entries := []*Tree{dir}
+	if dir.Type == Dir {
+		entries = dir.sortedChildren()
+	}
+	if opts['l'] {
+		fs.Root.populateDirSizes()
+	}
+	for _, e := range entries {
+		switch {
+		case opts['l']:
+			kind := "-"
+			if e.Type == Dir {
+				kind = "d"
+			}
+			fmt.Fprintf(out, "%v %10v %v\n", kind, e.Size, e.Name)
+		case e.Type == Dir:
+			fmt.Fprintf(out, "dir %v\n", e.Name)
+		default:
+			fmt.Fprintf(out, "%v %v\n", e.Size, e.Name)
+		}
+	}
+	return nil
+}
+
+// addLsEntry adds entry from ls output in format [dir <name>] or [<size> <name>],
+// entries listed twice are allowed as long as they don't change
+func (t *Tree) addLsEntry(line string) error {
+	parts := strings.Fields(line)
+	if len(parts) != 2 {
+		return fmt.Errorf("expected ls output format: [dir <name>] or [<size> <name>], got: %v", line)
+	}
+	name := parts[1]
+	existing, exists := t.Children[name]
+	if parts[0] == "dir" {
+		if exists && existing.Type != Dir {
+			return fmt.Errorf("%v: listed as directory, but it's a file", name)
+		}
+		if !exists {
+			t.Children[name] = newDir(name, t)
+		}
+		return nil
+	}
+	size, err := strconv.Atoi(parts[0])
+	if err != nil || size < 0 {
+		return fmt.Errorf("expected file size, got: %v", line)
+	}
+	if exists && (existing.Type != File || existing.Size != size) {
+		return fmt.Errorf("%v: listed as file of size %v, but it's already known as %v", name, size, existing.describe())
+	}
+	t.Children[name] = &Tree{Type: File, Size: size, Name: name, Parent: t}
+	return nil
+}
+
+func (t *Tree) describe() string {
+	if t.Type == Dir {
+		return "directory"
+	}
+	return fmt.Sprintf("file of size %v", t.Size)
+}
+
+// mkdir [-p] <dir>...
+func (fs *VFS) mkdir(args []string) error {
+	flags, paths := splitFlags(args)
+	opts, err := parseFlagLetters("mkdir", flags, "p")
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("expected: mkdir [-p] <dir>...")
+	}
+	for _, p := range paths {
+		if opts['p'] {
+			if err := fs.mkdirAll(p); err != nil {
+				return err
+			}
+			continue
+		}
+		parent, name, err := fs.resolveParent(p)
+		if err != nil {
+			return err
+		}
+		if _, ok := parent.Children[name]; ok {
+			return fmt.Errorf("%v: already exists", p)
+		}
+		parent.Children[name] = newDir(name, parent)
+	}
+	return nil
+}
+
+func (fs *VFS) mkdirAll(path string) error {
+	node := fs.Cwd
+	if strings.HasPrefix(path, ROOT_DIR) {
+		node = fs.Root
+	}
+	for _, part := range strings.Split(path, "/") {
+		switch part {
+		case "", ".":
+			continue
+		case GO_UP:
+			if node.Parent != nil {
+				node = node.Parent
+			}
+			continue
+		}
+		ch, ok := node.Children[part]
+		if !ok {
+			ch = newDir(part, node)
+			node.Children[part] = ch
+		}
+		if ch.Type != Dir {
+			return fmt.Errorf("%v: not a directory", ch.Path())
+		}
+		node = ch
+	}
+	return nil
+}
+
+// rm [-r] <path>..., directories can be removed only with -r
+func (fs *VFS) rm(args []string) error {
+	flags, paths := splitFlags(args)
+	opts, err := parseFlagLetters("rm", flags, "rf")
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("expected: rm [-r] <path>...")
+	}
+	for _, p := range paths {
+		node, err := fs.Resolve(p)
+		if err != nil {
+			return err
+		}
+		if node.Type == Dir && !opts['r'] {
+			return fmt.Errorf("%v: is a directory", p)
+		}
+		for cur := fs.Cwd; cur != nil; cur = cur.Parent {
+			if cur == node {
+				return fmt.Errorf("%v: can't remove current directory or its parent", p)
+			}
+		}
+		delete(node.Parent.Children, node.Name)
+		node.Parent = nil
+	}
+	return nil
+}
+
+// du [-a] [-h] [path], prints sizes in post-order like coreutils du does,
+// with -a files are printed too, with -h sizes are human readable
+func (fs *VFS) du(args []string, out io.Writer) error {
+	flags, paths := splitFlags(args)
+	opts, err := parseFlagLetters("du", flags, "ah")
+	if err != nil {
+		return err
+	}
+	if len(paths) > 1 {
+		return fmt.Errorf("expected: du [-a] [-h] [path]")
+	}
+	node := fs.Cwd
+	if len(paths) == 1 {
+		if node, err = fs.Resolve(paths[0]); err != nil {
+			return err
+		}
+	}
+	fs.Root.populateDirSizes()
+	format := strconv.Itoa
+	if opts['h'] {
+		format = HumanSize
+	}
+	return node.writeDu(out, opts['a'], format)
+}
+
+// find [path] [-type d|f] [-size [+|-]N] [-name pattern], sizes are in bytes:
+// +N means greater than N, -N less than N, N exactly N, pattern is matched against
+// the name of the node with shell glob syntax (*, ?, [a-z])
+func (fs *VFS) find(args []string, out io.Writer) error {
+	node := fs.Cwd
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		var err error
+		if node, err = fs.Resolve(args[0]); err != nil {
+			return err
+		}
+		args = args[1:]
+	}
+	conds, err := parseFindPredicates(args)
+	if err != nil {
+		return err
+	}
+	fs.Root.populateDirSizes()
+	for _, n := range node.Find(conds...) {
+		fmt.Fprintln(out, n.Path())
+	}
+	return nil
+}
+
+func parseFindPredicates(args []string) ([]func(*Tree) bool, error) {
+	conds := []func(*Tree) bool{}
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return nil, fmt.Errorf("find: missing argument for %v", args[i])
+		}
+		arg := args[i+1]
+		switch args[i] {
+		case "-type":
+			switch arg {
+			case "d":
+				conds = append(conds, func(t *Tree) bool { return t.Type == Dir })
+			case "f":
+				conds = append(conds, func(t *Tree) bool { return t.Type == File })
+			default:
+				return nil, fmt.Errorf("find: expected -type d or f, got: %v", arg)
+			}
+		case "-size":
+			cond, err := parseSizePredicate(arg)
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, cond)
+		case "-name":
+			if _, err := path.Match(arg, ""); err != nil {
+				return nil, fmt.Errorf("find: invalid pattern: %v", arg)
+			}
+			conds = append(conds, func(t *Tree) bool {
+				ok, _ := path.Match(arg, t.Name)
+				return ok
+			})
+		default:
+			return nil, fmt.Errorf("find: unsupported predicate: %v", args[i])
+		}
+	}
+	return conds, nil
+}
+
+func parseSizePredicate(arg string) (func(*Tree) bool, error) {
+	if arg == "" {
+		return nil, fmt.Errorf("find: expected -size [+|-]N, got empty string")
+	}
+	cmp := arg[:1]
+	num := arg
+	if cmp == "+" || cmp == "-" {
+		num = arg[1:]
+	}
+	size, err := strconv.Atoi(num)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("find: expected -size [+|-]N, got: %v", arg)
+	}
+	switch cmp {
+	case "+":
+		return func(t *Tree) bool { return t.Size > size }, nil
+	case "-":
+		return func(t *Tree) bool { return t.Size < size }, nil
+	default:
+		return func(t *Tree) bool { return t.Size == size }, nil
+	}
+}
+
+// HumanSize formats size with 1024 based units like du -h does, sizes are rounded up,
+// the unit is chosen after rounding, so 1048575 is 1.0M rather than 1024K
+func HumanSize(size int) string {
+	const units = "KMGTPE"
+	if size < 1024 {
+		return strconv.Itoa(size)
+	}
+	v := float64(size)
+	unit := 0
+	for v /= 1024; v >= 1024 && unit < len(units)-1; v /= 1024 {
+		unit++
+	}
+	// one decimal digit below 10, like du does
+	r := math.Ceil(v*10) / 10
+	if r >= 10 {
+		r = math.Ceil(v)
+	}
+	if r >= 1024 && unit < len(units)-1 {
+		r /= 1024
+		unit++
+	}
+	if r < 10 {
+		return fmt.Sprintf("%.1f%c", r, units[unit])
+	}
+	return fmt.Sprintf("%.0f%c", r, units[unit])
+}
@@ -5,47 +5,45 @@ import (
 	"strconv"
 )
 
-type VStatus int
-
-const (
-	NotVisible VStatus = iota
-	NotVisibleSameHight
-	Visible
+// ViewDirection is a step from tree to its neighbour, any non zero vector is supported
+type ViewDirection struct {
+	Name string
+	DX   int
+	DY   int
+}
+
+var (
+	ViewTop       = ViewDirection{"top", 0, -1}
+	ViewLeft      = ViewDirection{"left", -1, 0}
+	ViewBot       = ViewDirection{"bot", 0, 1}
+	ViewRight     = ViewDirection{"right", 1, 0}
+	ViewTopLeft   = ViewDirection{"top-left", -1, -1}
+	ViewTopRight  = ViewDirection{"top-right", 1, -1}
+	ViewBotLeft   = ViewDirection{"bot-left", -1, 1}
+	ViewBotRight  = ViewDirection{"bot-right", 1, 1}
+	CardinalViews = []ViewDirection{ViewTop, ViewLeft, ViewBot, ViewRight}
+	DiagonalViews = []ViewDirection{ViewTopLeft, ViewTopRight, ViewBotLeft, ViewBotRight}
+	AllViews      = append(append([]ViewDirection{}, CardinalViews...), DiagonalViews...)
 )
 
-type TreeInfo struct {
-	Hight  int
-	VLeft  VStatus
-	DLeft  int
-	VTop   VStatus
-	DTop   int
-	VRight VStatus
-	DRight int
-	VBot   VStatus
-	DBot   int
-}
-
-func (v TreeInfo) visibilityArea() int {
-	return v.DLeft * v.DBot * v.DTop * v.DRight
-}
-
-func To2DTreeInfoArray(ir InputReader) ([][]TreeInfo, error) {
+func ToTreeHeights(ir InputReader) ([][]int, error) {
 	content, err := ir.GetInput()
 	if err != nil {
 		return nil, err
 	}
 
-	out := [][]TreeInfo{}
-	for _, line := range content {
-		row := []TreeInfo{}
-		for i := 0; i < len(line); i++ {
-			parsed, err := strconv.Atoi(string(line[i]))
+	out := [][]int{}
+	for i, line := range content {
+		if len(out) > 0 && len(line) != len(out[0]) {
+			return nil, fmt.Errorf("expected rows of the same length: %v, got: %v in row %v", len(out[0]), len(line), i)
+		}
+		row := make([]int, len(line))
+		for j := 0; j < len(line); j++ {
+			parsed, err := strconv.Atoi(string(line[j]))
 			if err != nil {
 				return nil, err
 			}
-			row = append(row, TreeInfo{
-				Hight: parsed,
-			})
+			row[j] = parsed
 		}
 		out = append(out, row)
 	}
@@ -53,281 +51,164 @@ func To2DTreeInfoArray(ir InputReader) ([][]TreeInfo, error) {
 	return out, nil
 }
 
-// set visibility from top of particular tree
-func populateVisibilityTop(row int, col int, data [][]TreeInfo) {
-	v := &data[row][col]
-	top := data[row-1][col]
-	if v.Hight > top.Hight {
-		v.VTop = Visible
-		propagateVisiblityChangeToTop(v, row-1, col, data)
-	} else if v.Hight == top.Hight {
-		v.VTop = NotVisibleSameHight
-		v.DTop += 1
-	} else {
-		v.VTop = NotVisible
-		v.DTop += 1
-	}
+// ForestView keeps visibility from the edge and viewing distance of every tree for every direction,
+// Visible[d][row][col] and Distance[d][row][col] are for Directions[d]
+type ForestView struct {
+	Heights    [][]int
+	Directions []ViewDirection
+	Visible    [][][]bool
+	Distance   [][][]int
 }
 
-// update visibility from particular tree to every tree to the top
-func propagateVisiblityChangeToTop(initial *TreeInfo, fromRow int, col int, data [][]TreeInfo) {
-	for i := fromRow; i >= 0; i-- {
-		initial.DTop += 1
-		if data[i][col].Hight < initial.Hight {
-			data[i][col].VBot = NotVisible
-		} else if data[i][col].Hight == initial.Hight {
-			data[i][col].VBot = NotVisibleSameHight
-			initial.VTop = NotVisible
-			return
-		} else {
-			initial.VTop = NotVisible
-			return
+func AnalyzeForest(heights [][]int, dirs []ViewDirection) (ForestView, error) {
+	fv := ForestView{
+		Heights:    heights,
+		Directions: dirs,
+		Visible:    make([][][]bool, len(dirs)),
+		Distance:   make([][][]int, len(dirs)),
+	}
+	for d, dir := range dirs {
+		if dir.DX == 0 && dir.DY == 0 {
+			return ForestView{}, fmt.Errorf("direction [%v] mustn't be zero vector", dir.Name)
 		}
+		fv.Visible[d], fv.Distance[d] = scanDirection(heights, dir)
 	}
+	return fv, nil
 }
 
-// set visibility from left of particular tree
-func populateVisibilityLeft(row int, col int, data [][]TreeInfo) {
-	v := &data[row][col]
-	left := data[row][col-1]
-	if v.Hight > left.Hight {
-		v.VLeft = Visible
-		propagateVisiblityChangeToLeft(v, row, col-1, data)
-	} else if v.Hight == left.Hight {
-		v.VLeft = NotVisibleSameHight
-		v.DLeft += 1
-	} else {
-		v.VLeft = NotVisible
-		v.DLeft += 1
-	}
+type stackedTree struct {
+	pos   int
+	hight int
 }
 
-// update visibility from particular tree to every tree to the left
-func propagateVisiblityChangeToLeft(initial *TreeInfo, row int, fromCol int, data [][]TreeInfo) {
-	for i := fromCol; i >= 0; i-- {
-		initial.DLeft += 1
-		if data[row][i].Hight < initial.Hight {
-			data[row][i].VRight = NotVisible
-		} else if data[row][i].Hight == initial.Hight {
-			data[row][i].VRight = NotVisibleSameHight
-			initial.VLeft = NotVisible
-			return
-		} else {
-			initial.VLeft = NotVisible
-			return
-		}
+// scanDirection walks every line of trees along dir starting from the edge dir points to,
+// monotonic stack keeps trees which still can block the view, so every tree is pushed and popped once
+func scanDirection(heights [][]int, dir ViewDirection) ([][]bool, [][]int) {
+	visible := make([][]bool, len(heights))
+	dist := make([][]int, len(heights))
+	for i := range heights {
+		visible[i] = make([]bool, len(heights[i]))
+		dist[i] = make([]int, len(heights[i]))
 	}
-}
 
-// set visibility from bottom of particular tree
-func populateVisibilityBot(row int, col int, data [][]TreeInfo) {
-	v := &data[row][col]
-	bot := data[row+1][col]
-	if v.Hight > bot.Hight {
-		v.VBot = Visible
-		propagateVisiblityChangeToBot(v, row+1, col, data)
-	} else if v.Hight == bot.Hight {
-		v.VBot = NotVisibleSameHight
-		v.DBot += 1
-	} else {
-		v.VBot = NotVisible
-		v.DBot += 1
+	inside := func(row int, col int) bool {
+		return row >= 0 && row < len(heights) && col >= 0 && col < len(heights[row])
 	}
-}
 
-// update visibility from particular tree to every tree to the bottom
-func propagateVisiblityChangeToBot(initial *TreeInfo, fromRow int, col int, data [][]TreeInfo) {
-	for i := fromRow; i < len(data); i++ {
-		initial.DBot += 1
-		if data[i][col].Hight < initial.Hight {
-			data[i][col].VTop = NotVisible
-		} else if data[i][col].Hight == initial.Hight {
-			data[i][col].VTop = NotVisibleSameHight
-			initial.VBot = NotVisible
-			return
-		} else {
-			initial.VBot = NotVisible
-			return
+	stack := []stackedTree{}
+	for r := range heights {
+		for c := range heights[r] {
+			// line starts from the tree which is the last one in the direction
+			if inside(r+dir.DY, c+dir.DX) {
+				continue
+			}
+			stack = stack[:0]
+			for pos, row, col := 0, r, c; inside(row, col); pos, row, col = pos+1, row-dir.DY, col-dir.DX {
+				h := heights[row][col]
+				for len(stack) > 0 && stack[len(stack)-1].hight < h {
+					stack = stack[:len(stack)-1]
+				}
+				if len(stack) == 0 {
+					visible[row][col] = true
+					dist[row][col] = pos
+				} else {
+					dist[row][col] = pos - stack[len(stack)-1].pos
+				}
+				stack = append(stack, stackedTree{pos, h})
+			}
 		}
 	}
+	return visible, dist
 }
 
-// set visibility from right of particular tree
-func populateVisibilityRight(row int, col int, data [][]TreeInfo) {
-	v := &data[row][col]
-	right := data[row][col+1]
-	if v.Hight > right.Hight {
-		v.VRight = Visible
-		propagateVisiblityChangeToRight(v, row, col+1, data)
-	} else if v.Hight == right.Hight {
-		v.DRight += 1
-		v.VRight = NotVisibleSameHight
-	} else {
-		v.DRight += 1
-		v.VRight = NotVisible
+func (fv ForestView) IsVisible(row int, col int) bool {
+	for d := range fv.Directions {
+		if fv.Visible[d][row][col] {
+			return true
+		}
 	}
+	return false
 }
 
-// update visibility from particular tree to every tree to the right
-func propagateVisiblityChangeToRight(initial *TreeInfo, row int, fromCol int, data [][]TreeInfo) {
-	for i := fromCol; i < len(data[row]); i++ {
-		initial.DRight += 1
-		if data[row][i].Hight < initial.Hight {
-			data[row][i].VLeft = NotVisible
-		} else if data[row][i].Hight == initial.Hight {
-			data[row][i].VLeft = NotVisibleSameHight
-			initial.VRight = NotVisible
-			return
-		} else {
-			initial.VRight = NotVisible
-			return
-		}
+func (fv ForestView) ScenicScore(row int, col int) int {
+	score := 1
+	for d := range fv.Directions {
+		score *= fv.Distance[d][row][col]
 	}
+	return score
 }
 
-// Populate information about visibility and visibility area for each tree
-func populateVisibility(data [][]TreeInfo) {
-	for i := 0; i < len(data); i++ {
-		for j := 0; j < len(data[i]); j++ {
-			if i == 0 {
-				data[i][j].VTop = Visible
-			} else if i > 0 {
-				populateVisibilityTop(i, j, data)
-			}
-
-			if j == 0 {
-				data[i][j].VLeft = Visible
-			} else if j > 0 {
-				populateVisibilityLeft(i, j, data)
-			}
-
-			if i == len(data)-1 {
-				data[i][j].VBot = Visible
-				propagateVisiblityChangeToTop(&data[i][j], i-1, j, data)
-			} else if i < len(data)-1 {
-				populateVisibilityBot(i, j, data)
+// VisibilityGrid returns number of directions every tree is visible from
+func (fv ForestView) VisibilityGrid() [][]int {
+	res := make([][]int, len(fv.Heights))
+	for i := range fv.Heights {
+		res[i] = make([]int, len(fv.Heights[i]))
+		for j := range fv.Heights[i] {
+			for d := range fv.Directions {
+				if fv.Visible[d][i][j] {
+					res[i][j]++
+				}
 			}
+		}
+	}
+	return res
+}
 
-			if j == len(data[i])-1 {
-				data[i][j].VRight = Visible
-				propagateVisiblityChangeToLeft(&data[i][j], i, j-1, data)
-			} else if j < len(data[i])-1 {
-				populateVisibilityRight(i, j, data)
-			}
+func (fv ForestView) ScenicGrid() [][]int {
+	res := make([][]int, len(fv.Heights))
+	for i := range fv.Heights {
+		res[i] = make([]int, len(fv.Heights[i]))
+		for j := range fv.Heights[i] {
+			res[i][j] = fv.ScenicScore(i, j)
 		}
 	}
+	return res
 }
 
-func Task8_1(ir InputReader, cnvrtInpt func(ir InputReader) ([][]TreeInfo, error), debug bool) (string, error) {
+func Task8_1(ir InputReader, cnvrtInpt func(ir InputReader) ([][]int, error), debug bool) (string, error) {
 	data, err := cnvrtInpt(ir)
 	if err != nil {
 		return "", err
 	}
 
-	populateVisibility(data)
+	fv, err := AnalyzeForest(data, CardinalViews)
+	if err != nil {
+		return "", err
+	}
 
 	counter := 0
 	for i := 0; i < len(data); i++ {
 		for j := 0; j < len(data[i]); j++ {
-			item := data[i][j]
-			if item.VTop == Visible ||
-				item.VLeft == Visible ||
-				item.VBot == Visible ||
-				item.VRight == Visible {
+			if fv.IsVisible(i, j) {
 				counter++
 			}
 		}
 	}
 	if debug {
-		shortDebugVisibilityOutput(data)
-		fullDebugVisibilityOutput(data)
+		debugD8Grid(fv.VisibilityGrid(), "debug_d8_visibility")
 	}
 	return fmt.Sprintf("Result: %v", counter), nil
 }
 
-func Task8_2(ir InputReader, cnvrtInpt func(ir InputReader) ([][]TreeInfo, error), debug bool) (string, error) {
+func Task8_2(ir InputReader, cnvrtInpt func(ir InputReader) ([][]int, error), debug bool) (string, error) {
 	data, err := cnvrtInpt(ir)
 	if err != nil {
 		return "nil", err
 	}
 
-	populateVisibility(data)
-
-	maxArea := 0
-	for i := 1; i < len(data)-1; i++ {
-		for j := 1; j < len(data[i])-1; j++ {
-			item := data[i][j]
-			curArea := item.visibilityArea()
-			if curArea > maxArea {
-				maxArea = curArea
-			}
-		}
-	}
-	if debug {
-		shortDebugAreaOutput(data)
-	}
-	return fmt.Sprintf("Result: %v", maxArea), nil
-}
-
-func fullDebugVisibilityOutput(data [][]TreeInfo) {
-	fmt.Println("Full debug")
-	for i := 0; i < len(data); i++ {
-		for j := 0; j < len(data[i]); j++ {
-			item := data[i][j]
-			stat := "-"
-			if item.VTop == Visible ||
-				item.VLeft == Visible ||
-				item.VBot == Visible ||
-				item.VRight == Visible {
-				stat = "+"
-			}
-			var vt string = "-"
-			var vl string = "-"
-			var vb string = "-"
-			var vr string = "-"
-			if item.VTop == Visible {
-				vt = "+"
-			}
-			if item.VLeft == Visible {
-				vl = "+"
-			}
-			if item.VBot == Visible {
-				vb = "+"
-			}
-			if item.VRight == Visible {
-				vr = "+"
-			}
-			fmt.Printf("%v%v[t%vl%vb%vr%v]", item.Hight, stat, vt, vl, vb, vr)
-		}
-		fmt.Println()
+	fv, err := AnalyzeForest(data, CardinalViews)
+	if err != nil {
+		return "", err
 	}
-}
 
-func shortDebugVisibilityOutput(data [][]TreeInfo) {
-	fmt.Println("Short debug")
+	maxArea := 0
 	for i := 0; i < len(data); i++ {
 		for j := 0; j < len(data[i]); j++ {
-			item := data[i][j]
-			stat := "-"
-			if item.VTop == Visible ||
-				item.VLeft == Visible ||
-				item.VBot == Visible ||
-				item.VRight == Visible {
-				stat = "+"
-			}
-			fmt.Printf("%v%v", item.Hight, stat)
+			maxArea = Max(maxArea, fv.ScenicScore(i, j))
 		}
-		fmt.Println()
 	}
-}
-
-func shortDebugAreaOutput(data [][]TreeInfo) {
-	fmt.Println("Short debug")
-	for i := 0; i < len(data); i++ {
-		for j := 0; j < len(data[i]); j++ {
-			item := data[i][j]
-			fmt.Printf("[%v:%2d]", item.Hight, item.visibilityArea())
-		}
-		fmt.Println()
+	if debug {
+		debugD8Grid(fv.ScenicGrid(), "debug_d8_scenic")
 	}
+	return fmt.Sprintf("Result: %v", maxArea), nil
 }
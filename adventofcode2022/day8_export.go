@@ -0,0 +1,92 @@
+package adventofcode2022
+
+import (
+	"encoding/csv"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+)
+
+func WriteGridCSV(writer io.Writer, grid [][]int) error {
+	w := csv.NewWriter(writer)
+	for _, row := range grid {
+		rec := make([]string, len(row))
+		for i, v := range row {
+			rec[i] = strconv.Itoa(v)
+		}
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// heatColor maps value from [0, 1] to black -> red -> yellow -> white
+func heatColor(v float64) color.RGBA {
+	c := func(f float64) uint8 {
+		return uint8(255 * Minf(Maxf(f, 0), 1))
+	}
+	return color.RGBA{c(v * 3), c(v*3 - 1), c(v*3 - 2), 0xff}
+}
+
+// WriteHeatmapPNG draws every cell as scale x scale square, colors are normalized between min and max of grid
+func WriteHeatmapPNG(writer io.Writer, grid [][]int, scale int) error {
+	if scale < 1 {
+		return fmt.Errorf("expected scale >= 1, got: %v", scale)
+	}
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return fmt.Errorf("expected non empty grid")
+	}
+
+	minV, maxV := grid[0][0], grid[0][0]
+	for _, row := range grid {
+		for _, v := range row {
+			minV = Min(minV, v)
+			maxV = Max(maxV, v)
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, len(grid[0])*scale, len(grid)*scale))
+	for y, row := range grid {
+		for x, v := range row {
+			norm := 0.0
+			if maxV > minV {
+				norm = float64(v-minV) / float64(maxV-minV)
+			}
+			c := heatColor(norm)
+			for i := 0; i < scale; i++ {
+				for j := 0; j < scale; j++ {
+					img.Set(x*scale+j, y*scale+i, c)
+				}
+			}
+		}
+	}
+	return png.Encode(writer, img)
+}
+
+func debugD8Grid(grid [][]int, name string) {
+	f, err := os.Create(name + ".png")
+	if err != nil {
+		fmt.Printf("can't print debug: %v\n", err)
+	} else {
+		defer f.Close()
+		if err := WriteHeatmapPNG(f, grid, 4); err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+		}
+	}
+
+	f, err = os.Create(name + ".csv")
+	if err != nil {
+		fmt.Printf("can't print debug: %v\n", err)
+	} else {
+		defer f.Close()
+		if err := WriteGridCSV(f, grid); err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+		}
+	}
+}
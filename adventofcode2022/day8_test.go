@@ -0,0 +1,67 @@
+package adventofcode2022_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+var d8Example = [][]int{
+	{3, 0, 3, 7, 3},
+	{2, 5, 5, 1, 2},
+	{6, 5, 3, 3, 2},
+	{3, 3, 5, 4, 9},
+	{3, 5, 3, 9, 0},
+}
+
+func TestAnalyzeForest(t *testing.T) {
+	fv, err := adventofcode2022.AnalyzeForest(d8Example, adventofcode2022.CardinalViews)
+	assert.Nil(t, err)
+
+	visible := 0
+	maxScore := 0
+	for i := range d8Example {
+		for j := range d8Example[i] {
+			if fv.IsVisible(i, j) {
+				visible++
+			}
+			maxScore = adventofcode2022.Max(maxScore, fv.ScenicScore(i, j))
+		}
+	}
+	assert.Equal(t, 21, visible)
+	assert.Equal(t, 8, maxScore)
+	assert.Equal(t, 4, fv.ScenicScore(1, 2))
+}
+
+func TestAnalyzeForestDiagonals(t *testing.T) {
+	fv, err := adventofcode2022.AnalyzeForest(d8Example, adventofcode2022.DiagonalViews)
+	assert.Nil(t, err)
+
+	// middle tree with height 3 is blocked by 5 on the top-left, 1 and 3 on the top-right, 3 on the bot-left, 4 on the bot-right
+	assert.False(t, fv.IsVisible(2, 2))
+	assert.Equal(t, 2, fv.ScenicScore(2, 2))
+	// corner tree is visible from its corner and the opposite diagonal leads inside
+	assert.True(t, fv.IsVisible(0, 0))
+
+	_, err = adventofcode2022.AnalyzeForest(d8Example, []adventofcode2022.ViewDirection{{Name: "none"}})
+	assert.NotNil(t, err)
+}
+
+func TestForestExport(t *testing.T) {
+	fv, err := adventofcode2022.AnalyzeForest(d8Example, adventofcode2022.AllViews)
+	assert.Nil(t, err)
+
+	buf := bytes.Buffer{}
+	assert.Nil(t, adventofcode2022.WriteGridCSV(&buf, [][]int{{1, 2}, {3, 4}}))
+	assert.Equal(t, "1,2\n3,4\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, adventofcode2022.WriteHeatmapPNG(&buf, fv.ScenicGrid(), 3))
+	img, err := png.Decode(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 15, img.Bounds().Dx())
+	assert.Equal(t, 15, img.Bounds().Dy())
+}
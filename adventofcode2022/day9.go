@@ -64,38 +64,187 @@ type Point struct {
 	Y int
 }
 
+// FollowRule decides when a knot is too far from the knot ahead of it and how it moves to catch up
+type FollowRule interface {
+	Adjacent(head Point, tail Point) bool
+	// Step returns tail position after a single step towards head
+	Step(head Point, tail Point) Point
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// ChebyshevFollow is the puzzle rule: knots touching diagonally are adjacent, tail catches up moving diagonally
+type ChebyshevFollow struct {
+	MaxLag int
+}
+
+func (r ChebyshevFollow) Adjacent(head Point, tail Point) bool {
+	return Max(Abs(head.X-tail.X), Abs(head.Y-tail.Y)) <= r.MaxLag
+}
+
+func (r ChebyshevFollow) Step(head Point, tail Point) Point {
+	return Point{X: tail.X + sign(head.X-tail.X), Y: tail.Y + sign(head.Y-tail.Y)}
+}
+
+// ManhattanFollow allows only horizontal and vertical moves, tail moves along the axis with the largest distance
+type ManhattanFollow struct {
+	MaxLag int
+}
+
+func (r ManhattanFollow) Adjacent(head Point, tail Point) bool {
+	return Abs(head.X-tail.X)+Abs(head.Y-tail.Y) <= r.MaxLag
+}
+
+func (r ManhattanFollow) Step(head Point, tail Point) Point {
+	if Abs(head.X-tail.X) >= Abs(head.Y-tail.Y) {
+		return Point{X: tail.X + sign(head.X-tail.X), Y: tail.Y}
+	}
+	return Point{X: tail.X, Y: tail.Y + sign(head.Y-tail.Y)}
+}
+
+func DefaultFollowRule() FollowRule {
+	return ChebyshevFollow{MaxLag: 1}
+}
+
+// PositionRecorder receives positions of all knots before the first step and after every step
+type PositionRecorder interface {
+	Record(step int, knots []Point)
+}
+
+// TrajectoryRecorder keeps every position of every knot, Positions[knot][step]
+type TrajectoryRecorder struct {
+	Positions [][]Point
+}
+
+func (r *TrajectoryRecorder) Record(step int, knots []Point) {
+	if r.Positions == nil {
+		r.Positions = make([][]Point, len(knots))
+	}
+	for i, k := range knots {
+		r.Positions[i] = append(r.Positions[i], k)
+	}
+}
+
+func (r *TrajectoryRecorder) Visited(knot int) map[Point]bool {
+	res := map[Point]bool{}
+	if knot < len(r.Positions) {
+		for _, p := range r.Positions[knot] {
+			res[p] = true
+		}
+	}
+	return res
+}
+
+// VisitedRecorder keeps only unique positions, memory doesn't depend on number of moves
+type VisitedRecorder struct {
+	// knots to track, all if empty
+	Knots   []int
+	Visited map[int]map[Point]bool
+}
+
+func NewVisitedRecorder(knots ...int) *VisitedRecorder {
+	return &VisitedRecorder{
+		Knots:   knots,
+		Visited: map[int]map[Point]bool{},
+	}
+}
+
+// CheckKnots returns error if any tracked knot isn't in the rope of the given length
+func (r *VisitedRecorder) CheckKnots(knots int) error {
+	for _, k := range r.Knots {
+		if k < 0 || k >= knots {
+			return fmt.Errorf("can't track knot %v of the rope of %v knots", k, knots)
+		}
+	}
+	return nil
+}
+
+func (r *VisitedRecorder) record(knot int, p Point) {
+	if r.Visited[knot] == nil {
+		r.Visited[knot] = map[Point]bool{}
+	}
+	r.Visited[knot][p] = true
+}
+
+func (r *VisitedRecorder) Record(step int, knots []Point) {
+	if len(r.Knots) == 0 {
+		for i, k := range knots {
+			r.record(i, k)
+		}
+		return
+	}
+	for _, i := range r.Knots {
+		r.record(i, knots[i])
+	}
+}
+
 type KnotsState struct {
-	Knots     []Point
-	Recorders map[int]PosRecorder
+	Knots    []Point
+	Rule     FollowRule
+	Recorder PositionRecorder
+	Steps    int
 }
 
-type PosRecorder struct {
-	Positions []Point
+// KnotsChecker is implemented by recorders which track particular knots,
+// the recorder is checked against the rope length when it's attached
+type KnotsChecker interface {
+	CheckKnots(knots int) error
+}
+
+func NewKnotsState(knots int, rule FollowRule, recorder PositionRecorder) (*KnotsState, error) {
+	if knots < 1 {
+		return nil, fmt.Errorf("expected at least 1 knot, got: %v", knots)
+	}
+	if c, ok := recorder.(KnotsChecker); ok {
+		if err := c.CheckKnots(knots); err != nil {
+			return nil, err
+		}
+	}
+	s := &KnotsState{
+		Knots:    make([]Point, knots),
+		Rule:     rule,
+		Recorder: recorder,
+	}
+	s.Recorder.Record(0, s.Knots)
+	return s, nil
 }
 
 func (s *KnotsState) calcMove(move KnotMove) {
 	for i := 0; i < move.Count; i++ {
-		head := &s.Knots[0]
-		s.move1Step(head, move.Direction)
-		s.recordPoistion(0)
+		s.move1Step(&s.Knots[0], move.Direction)
 		for j := 1; j < len(s.Knots); j++ {
-			hIdx := j - 1
-			tIdx := j
-			curHead := &s.Knots[hIdx]
-			tail := &s.Knots[tIdx]
-			tm := s.determineTailAction(*curHead, *tail)
-			// if current knot haven't moved, other mustn't too
-			if len(tm) == 0 {
-				s.recordPoistion(tIdx)
+			head := s.Knots[j-1]
+			tail := &s.Knots[j]
+			// if current knot haven't moved, others won't move too
+			if s.Rule.Adjacent(head, *tail) {
 				break
 			}
-			// there're can be 2 moves if it's diagonal move, we don't need to record intermidiate tail poistion
-			// in this case, only the final
-			for _, m := range tm {
-				s.move1Step(tail, m.Direction)
+			for !s.Rule.Adjacent(head, *tail) {
+				next := s.Rule.Step(head, *tail)
+				// rule can't get any closer, e.g. negative lag
+				if next == *tail {
+					break
+				}
+				*tail = next
 			}
-			s.recordPoistion(tIdx)
 		}
+		s.Steps++
+		s.Recorder.Record(s.Steps, s.Knots)
+	}
+}
+
+func (s *KnotsState) Simulate(moves []KnotMove) {
+	for _, m := range moves {
+		s.calcMove(m)
 	}
 }
 
@@ -112,144 +261,83 @@ func (KnotsState) move1Step(head *Point, direction Direction) {
 	}
 }
 
-func (s *KnotsState) recordPoistion(knot int) {
-	_, ok := s.Recorders[knot]
-	if !ok {
-		s.Recorders[knot] = PosRecorder{Positions: []Point{}}
-	}
-	rcrd := s.Recorders[knot]
-	rcrd.Positions = append(rcrd.Positions, s.Knots[knot])
-	s.Recorders[knot] = rcrd
-}
+// number of steps written to debug_d9_frames.debug, the whole trajectory is too large to draw
+const d9DebugFrames = 100
 
-func (KnotsState) determineTailAction(h Point, t Point) []KnotMove {
-	// T and H intersects
-	if h.X == t.X && h.Y == t.Y {
-		return []KnotMove{}
-	}
-	// T and H on the same horizontal line
-	if h.X == t.X {
-		// T and H adjacement by vertical
-		if h.Y == t.Y+1 || h.Y == t.Y-1 {
-			return []KnotMove{}
-		} else {
-			diff := h.Y - t.Y
-			if diff > 0 {
-				return []KnotMove{{Direction: UP, Count: 1}}
-			} else {
-				return []KnotMove{{Direction: DOWN, Count: 1}}
-			}
-		}
-	}
-	// T and H on the same vertical line
-	if h.Y == t.Y {
-		// T and H adjacement by horizontal
-		if h.X == t.X+1 || h.X == t.X-1 {
-			return []KnotMove{}
-		} else {
-			diff := h.X - t.X
-			if diff > 0 {
-				return []KnotMove{{Direction: RIGHT, Count: 1}}
-			} else {
-				return []KnotMove{{Direction: LEFT, Count: 1}}
-			}
-		}
-	}
-	// T and H diagonal
-	if h.X != t.X && h.Y != t.Y {
-		// T and H adjacement by diagonal
-		if h.X == t.X-1 && h.Y == t.Y-1 ||
-			h.X == t.X-1 && h.Y == t.Y+1 ||
-			h.X == t.X+1 && h.Y == t.Y-1 ||
-			h.X == t.X+1 && h.Y == t.Y+1 {
-			return []KnotMove{}
-		} else {
-			diffX := h.X - t.X
-			diffY := h.Y - t.Y
-			if diffX > 0 && diffY > 0 {
-				return []KnotMove{{Direction: UP, Count: 1}, {Direction: RIGHT, Count: 1}}
-			} else if diffX > 0 && diffY < 0 {
-				return []KnotMove{{Direction: DOWN, Count: 1}, {Direction: RIGHT, Count: 1}}
-			} else if diffX < 0 && diffY > 0 {
-				return []KnotMove{{Direction: UP, Count: 1}, {Direction: LEFT, Count: 1}}
-			} else {
-				return []KnotMove{{Direction: DOWN, Count: 1}, {Direction: LEFT, Count: 1}}
-			}
+func countTailPositions(moves []KnotMove, knotsCount int, debug bool) (int, error) {
+	tail := knotsCount - 1
+	if !debug {
+		rec := NewVisitedRecorder(tail)
+		s, err := NewKnotsState(knotsCount, DefaultFollowRule(), rec)
+		if err != nil {
+			return 0, err
 		}
+		s.Simulate(moves)
+		return len(rec.Visited[tail]), nil
 	}
-	// Mustn't be here in any case
-	panic(fmt.Errorf("unexpected knots: head:%v, tail:%v", h, t))
-}
 
-func Task9_1(ir InputReader, cnvrtInpt func(InputReader) ([]KnotMove, error), debug bool) (string, error) {
-	moves, err := cnvrtInpt(ir)
+	rec := &TrajectoryRecorder{}
+	s, err := NewKnotsState(knotsCount, DefaultFollowRule(), rec)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
+	s.Simulate(moves)
 
-	knotsCount := 2
-	knots := make([]Point, knotsCount)
-	recorders := map[int]PosRecorder{}
-
-	state := KnotsState{
-		Knots:     knots,
-		Recorders: recorders,
-	}
-
-	for _, move := range moves {
-		state.calcMove(move)
+	f, err := os.Create("debug_d9.debug")
+	if err != nil {
+		fmt.Printf("can't crete debug file: %v", err)
+	} else {
+		defer f.Close()
+		debugOutput(rec.Positions[tail], f)
 	}
 
-	uniqueTailPos := map[Point]bool{}
-	for _, p := range state.Recorders[1].Positions {
-		uniqueTailPos[p] = true
+	f, err = os.Create("debug_d9_trajectory.csv")
+	if err != nil {
+		fmt.Printf("can't crete debug file: %v", err)
+	} else {
+		defer f.Close()
+		if err := rec.WriteCSV(f); err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
+		}
 	}
 
-	if debug {
-		f, err := os.Create("debug_d9.debug")
-		if err != nil {
-			fmt.Printf("can't crete debug file: %v", err)
+	f, err = os.Create("debug_d9_frames.debug")
+	if err != nil {
+		fmt.Printf("can't crete debug file: %v", err)
+	} else {
+		defer f.Close()
+		if err := rec.WriteFrames(f, 0, Min(s.Steps+1, d9DebugFrames)); err != nil {
+			fmt.Printf("can't print debug: %v\n", err)
 		}
-		debugOutput(state.Recorders[1].Positions, f)
 	}
 
-	return fmt.Sprintf("Result: %v", len(uniqueTailPos)), nil
+	return len(rec.Visited(tail)), nil
 }
 
-func Task9_2(ir InputReader, cnvrtInpt func(InputReader) ([]KnotMove, error), debug bool) (string, error) {
+func Task9_1(ir InputReader, cnvrtInpt func(InputReader) ([]KnotMove, error), debug bool) (string, error) {
 	moves, err := cnvrtInpt(ir)
 	if err != nil {
 		return "", err
 	}
 
-	knotsCount := 10
-	knots := make([]Point, knotsCount)
-	recorders := map[int]PosRecorder{}
-
-	state := KnotsState{
-		Knots:     knots,
-		Recorders: recorders,
-	}
-
-	for _, move := range moves {
-		state.calcMove(move)
+	res, err := countTailPositions(moves, 2, debug)
+	if err != nil {
+		return "", err
 	}
+	return fmt.Sprintf("Result: %v", res), nil
+}
 
-	uniqueTailPos := map[Point]bool{}
-	for _, p := range state.Recorders[9].Positions {
-		uniqueTailPos[p] = true
+func Task9_2(ir InputReader, cnvrtInpt func(InputReader) ([]KnotMove, error), debug bool) (string, error) {
+	moves, err := cnvrtInpt(ir)
+	if err != nil {
+		return "", err
 	}
 
-	if debug {
-		f, err := os.Create("debug_d9.debug")
-		if err != nil {
-			fmt.Printf("can't crete debug file: %v", err)
-		} else {
-			debugOutput(state.Recorders[9].Positions, f)
-		}
+	res, err := countTailPositions(moves, 10, debug)
+	if err != nil {
+		return "", err
 	}
-
-	return fmt.Sprintf("Result: %v", len(uniqueTailPos)), nil
+	return fmt.Sprintf("Result: %v", res), nil
 }
 
 func debugOutput(positions []Point, out io.Writer) {
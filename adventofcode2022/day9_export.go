@@ -0,0 +1,98 @@
+package adventofcode2022
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WriteCSV writes every position of every knot in format: step,knot,x,y
+func (r *TrajectoryRecorder) WriteCSV(writer io.Writer) error {
+	w := csv.NewWriter(writer)
+	if err := w.Write([]string{"step", "knot", "x", "y"}); err != nil {
+		return err
+	}
+	steps := 0
+	if len(r.Positions) > 0 {
+		steps = len(r.Positions[0])
+	}
+	for step := 0; step < steps; step++ {
+		for knot, positions := range r.Positions {
+			p := positions[step]
+			err := w.Write([]string{strconv.Itoa(step), strconv.Itoa(knot), strconv.Itoa(p.X), strconv.Itoa(p.Y)})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func knotSymbol(knot int, knots int) string {
+	switch {
+	case knot == 0:
+		return "H"
+	case knot == knots-1:
+		return "T"
+	case knot < 10:
+		return strconv.Itoa(knot)
+	default:
+		return "*"
+	}
+}
+
+// WriteFrames draws steps in range [from, to) like the puzzle does: H is head, T is tail, s is start,
+// all frames share the same viewport, so they can be played as animation, frames are separated by empty line
+func (r *TrajectoryRecorder) WriteFrames(writer io.Writer, from int, to int) error {
+	if len(r.Positions) == 0 {
+		return nil
+	}
+	steps := len(r.Positions[0])
+	if from < 0 || to > steps || from > to {
+		return fmt.Errorf("expected steps range within [0, %v], got: [%v, %v)", steps, from, to)
+	}
+
+	minX, minY := 0, 0
+	maxX, maxY := 0, 0
+	for _, positions := range r.Positions {
+		for _, p := range positions[from:to] {
+			minX = Min(minX, p.X)
+			minY = Min(minY, p.Y)
+			maxX = Max(maxX, p.X)
+			maxY = Max(maxY, p.Y)
+		}
+	}
+	if int64(maxX-minX+1)*int64(maxY-minY+1) > math.MaxInt32 {
+		return fmt.Errorf("viewport is too large: %vx%v", maxX-minX+1, maxY-minY+1)
+	}
+
+	for step := from; step < to; step++ {
+		frame := map[Point]string{{X: 0, Y: 0}: "s"}
+		// knots ahead cover knots behind them
+		for knot := len(r.Positions) - 1; knot >= 0; knot-- {
+			frame[r.Positions[knot][step]] = knotSymbol(knot, len(r.Positions))
+		}
+
+		s := strings.Builder{}
+		s.WriteString(fmt.Sprintf("== step %v ==\n", step))
+		for y := maxY; y >= minY; y-- {
+			for x := minX; x <= maxX; x++ {
+				if sym, ok := frame[Point{X: x, Y: y}]; ok {
+					s.WriteString(sym)
+				} else {
+					s.WriteString(".")
+				}
+			}
+			s.WriteString("\n")
+		}
+		s.WriteString("\n")
+		if _, err := io.WriteString(writer, s.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
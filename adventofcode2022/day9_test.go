@@ -0,0 +1,96 @@
+package adventofcode2022_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/stretchr/testify/assert"
+)
+
+func d9Moves(s string) []adventofcode2022.KnotMove {
+	moves := []adventofcode2022.KnotMove{}
+	for _, f := range strings.Split(s, ",") {
+		d, _ := adventofcode2022.DirectionOf(f[:1])
+		c, _ := strconv.Atoi(f[1:])
+		moves = append(moves, adventofcode2022.KnotMove{Direction: d, Count: c})
+	}
+	return moves
+}
+
+func TestRopeFollowRules(t *testing.T) {
+	small := d9Moves("R4,U4,L3,D1,R4,D1,L5,R2")
+	large := d9Moves("R5,U8,L8,D3,R17,D10,L25,U20")
+
+	tt := []struct {
+		name     string
+		moves    []adventofcode2022.KnotMove
+		knots    int
+		rule     adventofcode2022.FollowRule
+		expected int
+	}{
+		{"2 knots", small, 2, adventofcode2022.DefaultFollowRule(), 13},
+		{"10 knots", small, 10, adventofcode2022.DefaultFollowRule(), 1},
+		{"10 knots, large", large, 10, adventofcode2022.DefaultFollowRule(), 36},
+		{"2 knots, lag 2", d9Moves("R5"), 2, adventofcode2022.ChebyshevFollow{MaxLag: 2}, 4},
+		{"2 knots, manhattan", d9Moves("R1,U1,U1"), 2, adventofcode2022.ManhattanFollow{MaxLag: 1}, 3},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			visited := adventofcode2022.NewVisitedRecorder(tc.knots - 1)
+			simulate(t, tc.knots, tc.rule, visited, tc.moves)
+			assert.Len(t, visited.Visited[tc.knots-1], tc.expected)
+
+			trajectory := &adventofcode2022.TrajectoryRecorder{}
+			simulate(t, tc.knots, tc.rule, trajectory, tc.moves)
+			assert.Equal(t, visited.Visited[tc.knots-1], trajectory.Visited(tc.knots-1))
+		})
+	}
+}
+
+func TestRopeManhattanMovesOrthogonally(t *testing.T) {
+	trajectory := &adventofcode2022.TrajectoryRecorder{}
+	simulate(t, 3, adventofcode2022.ManhattanFollow{MaxLag: 1}, trajectory, d9Moves("R3,U3,L3"))
+	for _, positions := range trajectory.Positions {
+		for i := 1; i < len(positions); i++ {
+			dx := positions[i].X - positions[i-1].X
+			dy := positions[i].Y - positions[i-1].Y
+			assert.False(t, dx != 0 && dy != 0, "diagonal move: %v -> %v", positions[i-1], positions[i])
+		}
+	}
+}
+
+func TestRopeExport(t *testing.T) {
+	trajectory := &adventofcode2022.TrajectoryRecorder{}
+	simulate(t, 2, adventofcode2022.DefaultFollowRule(), trajectory, d9Moves("R2,U1"))
+
+	csvBuf := bytes.Buffer{}
+	assert.Nil(t, trajectory.WriteCSV(&csvBuf))
+	assert.Equal(t, "step,knot,x,y\n0,0,0,0\n0,1,0,0\n1,0,1,0\n1,1,0,0\n2,0,2,0\n2,1,1,0\n3,0,2,1\n3,1,1,0\n", csvBuf.String())
+
+	frames := bytes.Buffer{}
+	assert.Nil(t, trajectory.WriteFrames(&frames, 2, 4))
+	assert.Equal(t, "== step 2 ==\n...\nsTH\n\n== step 3 ==\n..H\nsT.\n\n", frames.String())
+
+	assert.NotNil(t, trajectory.WriteFrames(&frames, 3, 10))
+}
+
+func simulate(t *testing.T, knots int, rule adventofcode2022.FollowRule, rec adventofcode2022.PositionRecorder, moves []adventofcode2022.KnotMove) {
+	s, err := adventofcode2022.NewKnotsState(knots, rule, rec)
+	assert.Nil(t, err)
+	s.Simulate(moves)
+}
+
+func TestVisitedRecorderKnots(t *testing.T) {
+	_, err := adventofcode2022.NewKnotsState(2, adventofcode2022.DefaultFollowRule(), adventofcode2022.NewVisitedRecorder(5))
+	assert.NotNil(t, err)
+	_, err = adventofcode2022.NewKnotsState(2, adventofcode2022.DefaultFollowRule(), adventofcode2022.NewVisitedRecorder(-1))
+	assert.NotNil(t, err)
+	_, err = adventofcode2022.NewKnotsState(0, adventofcode2022.DefaultFollowRule(), adventofcode2022.NewVisitedRecorder())
+	assert.NotNil(t, err)
+	_, err = adventofcode2022.NewKnotsState(2, adventofcode2022.DefaultFollowRule(), adventofcode2022.NewVisitedRecorder(0, 1))
+	assert.Nil(t, err)
+}
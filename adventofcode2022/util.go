@@ -21,13 +21,28 @@ type FileToStringsInputReader struct {
 }
 
 func (fts *FileToStringsInputReader) GetInput() ([]string, error) {
-	f, err := os.Open(fts.Path)
+	lines := []string{}
+	err := fts.ScanLines(func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	return lines, nil
+}
 
-	lines := []string{}
+// LineScanner is an InputReader which can pass lines one by one without loading the whole input
+type LineScanner interface {
+	ScanLines(fn func(line string) error) error
+}
+
+func (fts *FileToStringsInputReader) ScanLines(fn func(line string) error) error {
+	f, err := os.Open(fts.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
 	sc := bufio.NewScanner(f)
 	for sc.Scan() {
@@ -35,14 +50,28 @@ func (fts *FileToStringsInputReader) GetInput() ([]string, error) {
 		if fts.Opts.TrimLine {
 			line = strings.TrimSpace(line)
 		}
-		lines = append(lines, line)
+		if err := fn(line); err != nil {
+			return err
+		}
 	}
+	return sc.Err()
+}
 
-	if err := sc.Err(); err != nil {
-		return nil, err
+// ScanInput passes lines to fn one by one, the whole input is loaded only if ir isn't a LineScanner
+func ScanInput(ir InputReader, fn func(line string) error) error {
+	if ls, ok := ir.(LineScanner); ok {
+		return ls.ScanLines(fn)
 	}
-
-	return lines, nil
+	lines, err := ir.GetInput()
+	if err != nil {
+		return err
+	}
+	for _, l := range lines {
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func ToSingleLine(ir InputReader) (string, error) {
@@ -89,6 +118,20 @@ func Min64(arg1 int64, arg2 int64) int64 {
 	return arg2
 }
 
+func Minf(arg1 float64, arg2 float64) float64 {
+	if arg1 < arg2 {
+		return arg1
+	}
+	return arg2
+}
+
+func Maxf(arg1 float64, arg2 float64) float64 {
+	if arg1 > arg2 {
+		return arg1
+	}
+	return arg2
+}
+
 func Abs(arg int) int {
 	if arg > 0 {
 		return arg
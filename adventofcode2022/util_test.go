@@ -0,0 +1,8 @@
+package adventofcode2022_test
+
+// linesReader is an InputReader over lines kept in memory
+type linesReader []string
+
+func (lr linesReader) GetInput() ([]string, error) {
+	return lr, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/asstart/advent-of-code-2022/adventofcode2022"
+	"github.com/jessevdk/go-flags"
+)
+
+func addCommands(parser *flags.Parser) {
+	parser.AddCommand("debug", "Step debugger for day 10 CPU", "Loads day 10 program and reads debugger commands from stdin", &debugCmd{})
+	parser.AddCommand("fs", "Shell over day 7 filesystem", "Replays day 7 terminal output and reads shell commands from stdin", &fsCmd{})
+	parser.AddCommand("sections", "Relations of day 4 section assignments", "Prints histogram of Allen's interval relations between assignments of every pair", &sectionsCmd{})
+	parser.AddCommand("coverage", "Coverage of sections by day 4 assignments", "Prints union of assignments, unassigned sections, max overlap and minimal set of assignments covering the same sections", &coverageCmd{})
+	parser.AddCommand("packets", "Explain comparison of two day 13 packets", "Prints every step of comparison of two packets given as arguments", &packetsCmd{})
+	parser.AddCommand("route", "Shortest route over day 12 heightmap", "Finds the route from S to E with the chosen algorithm and climbing rules and draws it", &routeCmd{})
+}
+
+type debugCmd struct {
+	File      string `short:"f" long:"file" default:"adventofcode2022/day10.data" description:"Day 10 program"`
+	MaxCycles int    `long:"max-cycles" default:"1000000" description:"Max cycles of a single step, next or continue, 0 means no limit"`
+}
+
+func (c *debugCmd) Execute(args []string) error {
+	program, err := adventofcode2022.ToProgram(&adventofcode2022.FileToStringsInputReader{Path: c.File})
+	if err != nil {
+		return err
+	}
+	d := adventofcode2022.NewDebugger(program, os.Stdin, os.Stdout)
+	d.MaxCycles = c.MaxCycles
+	return d.Run()
+}
+
+type fsCmd struct {
+	File string `short:"f" long:"file" default:"adventofcode2022/day7.data" description:"Day 7 terminal output"`
+}
+
+func (c *fsCmd) Execute(args []string) error {
+	cmdQueue, err := adventofcode2022.ToCmdQueue(&adventofcode2022.FileToStringsInputReader{Path: c.File})
+	if err != nil {
+		return err
+	}
+	fs := adventofcode2022.NewVFS()
+	if err := fs.Replay(cmdQueue); err != nil {
+		return err
+	}
+	fs.Cwd = fs.Root
+	return adventofcode2022.NewShell(fs, os.Stdin, os.Stdout).Run()
+}
+
+type sectionsCmd struct {
+	File string `short:"f" long:"file" default:"adventofcode2022/day4.data" description:"Day 4 section assignments"`
+}
+
+func (c *sectionsCmd) Execute(args []string) error {
+	pairs, err := adventofcode2022.ToTupleSegment(&adventofcode2022.FileToStringsInputReader{Path: c.File})
+	if err != nil {
+		return err
+	}
+	if err := adventofcode2022.WriteRelationReport(os.Stdout, adventofcode2022.RelationHistogram(pairs), 40); err != nil {
+		return err
+	}
+	fmt.Printf("fully contained: %v\n", adventofcode2022.CountRelations(pairs, adventofcode2022.FullyContained))
+	fmt.Printf("overlapping: %v\n", adventofcode2022.CountRelations(pairs, adventofcode2022.Overlapping))
+	return nil
+}
+
+type coverageCmd struct {
+	File string `short:"f" long:"file" default:"adventofcode2022/day4.data" description:"Day 4 section assignments"`
+	From int    `long:"from" description:"First section to check, 0 means the first assigned section"`
+	To   int    `long:"to" description:"Last section to check, 0 means the last assigned section"`
+}
+
+func (c *coverageCmd) Execute(args []string) error {
+	pairs, err := adventofcode2022.ToTupleSegment(&adventofcode2022.FileToStringsInputReader{Path: c.File})
+	if err != nil {
+		return err
+	}
+	segs := adventofcode2022.Segments(pairs)
+	rng, err := adventofcode2022.SegmentsRange(segs)
+	if err != nil {
+		return err
+	}
+	from, to := rng.Start(), rng.End()
+	if c.From != 0 {
+		from = c.From
+	}
+	if c.To != 0 {
+		to = c.To
+	}
+	within, err := adventofcode2022.NewSegment(from, to)
+	if err != nil {
+		return err
+	}
+	return adventofcode2022.AnalyzeCoverage(segs, within).WriteReport(os.Stdout)
+}
+
+type packetsCmd struct{}
+
+func (c *packetsCmd) Execute(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected two packets, got: %v", len(args))
+	}
+	l, err := adventofcode2022.ParsePacket(args[0])
+	if err != nil {
+		return err
+	}
+	r, err := adventofcode2022.ParsePacket(args[1])
+	if err != nil {
+		return err
+	}
+	cr, steps := l.CompareTrace(r)
+	if err := adventofcode2022.WriteTrace(os.Stdout, steps); err != nil {
+		return err
+	}
+	fmt.Printf("right order: %v\n", cr < 0)
+	return nil
+}
+
+type routeCmd struct {
+	File       string `short:"f" long:"file" default:"adventofcode2022/day12.data" description:"Day 12 heightmap"`
+	Algorithm  string `short:"a" long:"algorithm" default:"bfs" choice:"bfs" choice:"dijkstra" choice:"astar" description:"Pathfinding algorithm"`
+	MaxAscent  int    `long:"max-ascent" default:"1" description:"Max ascent of a single step, negative means no limit"`
+	MaxDescent int    `long:"max-descent" default:"-1" description:"Max descent of a single step, negative means no limit"`
+}
+
+func (c *routeCmd) Execute(args []string) error {
+	m, err := adventofcode2022.ToElevationMap(&adventofcode2022.FileToStringsInputReader{Path: c.File})
+	if err != nil {
+		return err
+	}
+	rules := adventofcode2022.ClimbRules{MaxAscent: c.MaxAscent, MaxDescent: c.MaxDescent}
+	res, err := adventofcode2022.PathFinders[c.Algorithm].FindPath(m, m.Start, m.Finish, rules)
+	if err != nil {
+		return err
+	}
+	if !res.Found() {
+		return fmt.Errorf("no route from %v to %v, explored: %v", m.Start, m.Finish, res.Explored)
+	}
+	if err := adventofcode2022.WriteRoute(os.Stdout, m, res.Path); err != nil {
+		return err
+	}
+	fmt.Printf("steps: %v, explored: %v\n", res.Steps(), res.Explored)
+	return nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"sort"
@@ -14,15 +15,35 @@ type opts struct {
 	N string `short:"n"  description:"Number of task in format day_part, like 1_1, 1_2"`
 	A bool   `short:"a"  description:"Run all tasks"`
 	D bool   `short:"d" description:"Debug mode"`
+	// used by day 2 only
+	Rules string `long:"rules" description:"Day 2 rule file in JSON, rock paper scissors by default"`
 }
 
 func main() {
 	var o opts
-	if _, err := flags.Parse(&o); err != nil {
-		fmt.Printf("error while parsing flags: %s\n", err)
+	// errors aren't printed by parser, errors of subcommands aren't related to flags
+	parser := flags.NewParser(&o, flags.HelpFlag|flags.PassDoubleDash)
+	parser.SubcommandsOptional = true
+	addCommands(parser)
+	if _, err := parser.Parse(); err != nil {
+		var flagsErr *flags.Error
+		switch {
+		case errors.As(err, &flagsErr) && flagsErr.Type == flags.ErrHelp:
+			fmt.Println(err)
+			os.Exit(0)
+		case errors.As(err, &flagsErr):
+			fmt.Printf("error while parsing flags: %s\n", err)
+		default:
+			fmt.Printf("error: %s\n", err)
+		}
 		os.Exit(1)
 	}
 
+	// subcommands are executed by parser
+	if parser.Active != nil {
+		os.Exit(0)
+	}
+
 	if o.N != "" && o.A {
 		fmt.Printf("options (a, n) mustn't be used simultaneously, choose one!\n")
 		os.Exit(1)
@@ -123,10 +144,11 @@ func runTask(o opts) {
 func t1_1(o opts) string {
 	res, err := adventofcode2022.Task1_1(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day1.data"},
-		adventofcode2022.ToIntOrSpaceArr,
+		adventofcode2022.ToCalorieItems,
+		o.D,
 	)
 	if err != nil {
-		res = err.Error()
+		return err.Error()
 	}
 	return res
 }
@@ -134,7 +156,8 @@ func t1_1(o opts) string {
 func t1_2(o opts) string {
 	res, err := adventofcode2022.Task1_2(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day1.data"},
-		adventofcode2022.ToIntOrSpaceArr,
+		adventofcode2022.ToCalorieItems,
+		o.D,
 	)
 	if err != nil {
 		return err.Error()
@@ -145,7 +168,9 @@ func t1_2(o opts) string {
 func t2_1(o opts) string {
 	res, err := adventofcode2022.Task2_1(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day2.data"},
-		adventofcode2022.ToTupleRPSArr,
+		adventofcode2022.ToRounds,
+		o.Rules,
+		o.D,
 	)
 	if err != nil {
 		return err.Error()
@@ -156,7 +181,9 @@ func t2_1(o opts) string {
 func t2_2(o opts) string {
 	res, err := adventofcode2022.Task2_2(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day2.data"},
-		adventofcode2022.ToTupleRPSArr,
+		adventofcode2022.ToRounds,
+		o.Rules,
+		o.D,
 	)
 	if err != nil {
 		return err.Error()
@@ -167,7 +194,7 @@ func t2_2(o opts) string {
 func t3_1(o opts) string {
 	res, err := adventofcode2022.Task3_1(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day3.data"},
-		adventofcode2022.ToTupleIntArr,
+		adventofcode2022.ToRucksacks,
 	)
 	if err != nil {
 		return err.Error()
@@ -178,7 +205,7 @@ func t3_1(o opts) string {
 func t3_2(o opts) string {
 	res, err := adventofcode2022.Task3_2(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day3.data"},
-		adventofcode2022.To3DArray,
+		adventofcode2022.ToRucksacks,
 	)
 	if err != nil {
 		return err.Error()
@@ -256,6 +283,7 @@ func t7_1(o opts) string {
 	res, err := adventofcode2022.Task7_1(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day7.data"},
 		adventofcode2022.ToCmdQueue,
+		o.D,
 	)
 	if err != nil {
 		return err.Error()
@@ -267,6 +295,7 @@ func t7_2(o opts) string {
 	res, err := adventofcode2022.Task7_2(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day7.data"},
 		adventofcode2022.ToCmdQueue,
+		o.D,
 	)
 	if err != nil {
 		return err.Error()
@@ -277,7 +306,7 @@ func t7_2(o opts) string {
 func t8_1(o opts) string {
 	res, err := adventofcode2022.Task8_1(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day8.data"},
-		adventofcode2022.To2DTreeInfoArray,
+		adventofcode2022.ToTreeHeights,
 		o.D,
 	)
 	if err != nil {
@@ -289,7 +318,7 @@ func t8_1(o opts) string {
 func t8_2(o opts) string {
 	res, err := adventofcode2022.Task8_2(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day8.data"},
-		adventofcode2022.To2DTreeInfoArray,
+		adventofcode2022.ToTreeHeights,
 		o.D,
 	)
 	if err != nil {
@@ -325,7 +354,7 @@ func t9_2(o opts) string {
 func t10_1(o opts) string {
 	res, err := adventofcode2022.Task10_1(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day10.data"},
-		adventofcode2022.ToStatefulCmds,
+		adventofcode2022.ToProgram,
 		o.D,
 	)
 	if err != nil {
@@ -337,7 +366,7 @@ func t10_1(o opts) string {
 func t10_2(o opts) string {
 	res, err := adventofcode2022.Task10_2(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day10.data"},
-		adventofcode2022.ToStatefulCmds,
+		adventofcode2022.ToProgram,
 		o.D,
 	)
 	if err != nil {
@@ -404,7 +433,7 @@ func t12_2(o opts) string {
 func t13_1(o opts) string {
 	res, err := adventofcode2022.Task13_1(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day13.data"},
-		adventofcode2022.ToArrTupleString,
+		adventofcode2022.ToPacketPairs,
 		o.D,
 	)
 	if err != nil {
@@ -416,7 +445,7 @@ func t13_1(o opts) string {
 func t13_2(o opts) string {
 	res, err := adventofcode2022.Task13_2(
 		&adventofcode2022.FileToStringsInputReader{Path: "adventofcode2022/day13.data"},
-		adventofcode2022.ToArrTupleString,
+		adventofcode2022.ToPacketPairs,
 		o.D,
 	)
 	if err != nil {